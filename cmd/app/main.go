@@ -7,14 +7,21 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"tender-bridge/cmd/app/scheduler"
 	"tender-bridge/cmd/app/server"
 	"tender-bridge/config"
 	"tender-bridge/internal/cache"
 	"tender-bridge/internal/handler"
+	"tender-bridge/internal/mailer"
 	"tender-bridge/internal/repository"
 	"tender-bridge/internal/service"
+	"tender-bridge/pkg/jwks"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/oidc"
+	"tender-bridge/pkg/sealer"
 	"tender-bridge/pkg/setup"
+	"tender-bridge/pkg/validator"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
@@ -31,6 +38,10 @@ func main() {
 	cfg := config.GetConfig()
 	logger := logger.GetLogger()
 
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal(err)
+	}
+
 	db, err := setup.SetupPostgresConnection(cfg)
 	if err != nil {
 		logger.Fatal(err)
@@ -43,8 +54,33 @@ func main() {
 	})
 	redisCache := cache.NewRedisCache(redisClient)
 
+	mailSender, err := mailer.NewSender(cfg, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	keySet, err := jwks.NewKeySet(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	providers, err := oidc.LoadProviders(cfg.OIDCProvidersFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	passwords, err := validator.NewPasswordPolicy(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	bidSealer, err := sealer.NewSealer(cfg.BidSealKey)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	repos := repository.NewRepository(db, logger)
-	services := service.NewService(repos, redisCache, cfg, logger)
+	services := service.NewService(repos, redisCache, mailSender, keySet, providers, passwords, bidSealer, cfg, logger)
 	handlers := handler.NewHandler(services, logger)
 
 	srv := new(server.Server)
@@ -54,6 +90,15 @@ func main() {
 		}
 	}()
 
+	jobs := scheduler.NewScheduler(logger)
+	jobs.Every("close_expired_tenders", time.Duration(cfg.TenderCloseIntervalSeconds)*time.Second, func() error {
+		closed, err := services.Tender.CloseExpiredTenders()
+		if closed > 0 {
+			logger.Infof("closed %d expired tenders", closed)
+		}
+		return err
+	})
+
 	logger.Info("App started")
 
 	quit := make(chan os.Signal, 1)
@@ -66,6 +111,8 @@ func main() {
 		logger.Errorf("error occured on server shutting down: %s", err.Error())
 	}
 
+	jobs.Shutdown()
+
 	if err := db.Close(); err != nil {
 		logger.Errorf("error occured on db connection close: %s", err.Error())
 	}
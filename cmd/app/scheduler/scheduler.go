@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"tender-bridge/pkg/logger"
+	"time"
+)
+
+// Job is a unit of background work. Jobs must be safe to run on several
+// replicas at once, the scheduler does not coordinate between them.
+type Job func() error
+
+type Scheduler struct {
+	logger *logger.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(logger *logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Every runs the job right away and then once per interval until Shutdown.
+// The interval must be positive.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	if interval <= 0 {
+		s.logger.Errorf("scheduled job %s not started: invalid interval %s", name, interval)
+		return
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := job(); err != nil {
+				s.logger.Errorf("scheduled job %s failed: %s", name, err.Error())
+			}
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops scheduling and waits for running jobs to finish
+func (s *Scheduler) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/setup"
+	"tender-bridge/pkg/validator"
+)
+
+var flags = flag.NewFlagSet("bootstrap", flag.ExitOnError)
+
+// Creates the first admin account. Further admins are promoted through the
+// admin API, so the command refuses to run once any admin exists.
+func main() {
+	username := flags.String("username", "", "admin username")
+	email := flags.String("email", "", "admin email")
+	password := flags.String("password", os.Getenv("ADMIN_PASSWORD"), "admin password, defaults to $ADMIN_PASSWORD")
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	if *username == "" || *email == "" || *password == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.GetConfig()
+	logger := logger.GetLogger()
+
+	db, err := setup.SetupPostgresConnection(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repos := repository.NewRepository(db, logger)
+
+	_, total, err := repos.User.GetList(models.UserFilter{
+		Role:  config.RoleAdmin,
+		Limit: 1,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if total > 0 {
+		log.Fatal("an admin account already exists")
+	}
+
+	passwords, err := validator.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = passwords.Validate("password", *password, *username, *email); err != nil {
+		log.Fatal(err)
+	}
+
+	hash, err := helper.GenerateHash(*password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	id, err := repos.User.Create(models.CreateUser{
+		Role:     config.RoleAdmin,
+		Username: *username,
+		Email:    *email,
+		Password: hash,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = repos.User.MarkEmailVerified(id); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("admin %s created with id %s\n", *username, id)
+}
+
+func usage() {
+	fmt.Println("Usage: bootstrap -username NAME -email EMAIL [-password PASSWORD]")
+	fmt.Println("Options:")
+	flags.PrintDefaults()
+}
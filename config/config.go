@@ -1,12 +1,18 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cast"
 )
 
+// defaultBidSealKey is public, so it only protects sealed bids in
+// development
+const defaultBidSealKey = "tender-bridge-sealed-bids"
+
 var (
 	instance *Config
 	once     sync.Once
@@ -16,6 +22,10 @@ type Config struct {
 	HTTPHost string
 	HTTPPort int
 
+	// TrustedProxies lists the proxies whose forwarding headers are used to
+	// find the client IP
+	TrustedProxies []string
+
 	Environment string
 	Debug       bool
 
@@ -33,17 +43,51 @@ type Config struct {
 	JWTSecret                string
 	JWTAccessExpirationHours int
 	JWTRefreshExpirationDays int
+	JWTKeysDir               string
+	JWTSigningKeyId          string
+	JWTLegacySecretUntil     string
 
 	HashKey string
+
+	AppBaseURL string
+
+	MailDriver string
+	MailFrom   string
+	MailDir    string
+
+	PasswordResetExpirationMinutes   int
+	EmailVerificationExpirationHours int
+	InvitationExpirationHours        int
+
+	MFAIssuer string
+
+	PasswordMinLength        int
+	PasswordRequireLowercase bool
+	PasswordRequireUppercase bool
+	PasswordRequireDigit     bool
+	PasswordRequireSymbol    bool
+	PasswordBreachedListFile string
+
+	LoginMaxAttempts      int
+	LoginMaxAttemptsPerIP int
+	LoginLockoutMinutes   int
+
+	OIDCProvidersFile string
+
+	TenderCloseIntervalSeconds int
+	BidSealKey                 string
+	ClarificationCutoffHours   int
 }
 
 func GetConfig() *Config {
 	once.Do(func() {
 		instance = &Config{
-			HTTPHost:    cast.ToString(getOrReturnDefault("HOST", "localhost")),
-			HTTPPort:    cast.ToInt(getOrReturnDefault("PORT", 8888)),
-			Environment: cast.ToString(getOrReturnDefault("ENVIRONMENT", "development")),
-			Debug:       cast.ToBool(getOrReturnDefault("DEBUG", true)),
+			HTTPHost: cast.ToString(getOrReturnDefault("HOST", "localhost")),
+			HTTPPort: cast.ToInt(getOrReturnDefault("PORT", 8888)),
+
+			TrustedProxies: splitList(cast.ToString(getOrReturnDefault("TRUSTED_PROXIES", ""))),
+			Environment:    cast.ToString(getOrReturnDefault("ENVIRONMENT", EnvironmentDevelopment)),
+			Debug:          cast.ToBool(getOrReturnDefault("DEBUG", true)),
 
 			PostgresHost:     cast.ToString(getOrReturnDefault("POSTGRES_HOST", "db")),
 			PostgresPort:     cast.ToInt(getOrReturnDefault("POSTGRES_PORT", 5432)),
@@ -59,14 +103,60 @@ func GetConfig() *Config {
 			JWTSecret:                cast.ToString(getOrReturnDefault("JWT_SECRET", "tender-bridge-forever")),
 			JWTAccessExpirationHours: cast.ToInt(getOrReturnDefault("JWT_ACCESS_EXPIRATION_HOURS", 12)),
 			JWTRefreshExpirationDays: cast.ToInt(getOrReturnDefault("JWT_REFRESH_EXPIRATION_DAYS", 3)),
+			JWTKeysDir:               cast.ToString(getOrReturnDefault("JWT_KEYS_DIR", "")),
+			JWTSigningKeyId:          cast.ToString(getOrReturnDefault("JWT_SIGNING_KEY_ID", "")),
+			JWTLegacySecretUntil:     cast.ToString(getOrReturnDefault("JWT_LEGACY_SECRET_UNTIL", "")),
 
 			HashKey: cast.ToString(getOrReturnDefault("HASH_KEY", "skd32r8wdahHSdqw")),
+
+			AppBaseURL: cast.ToString(getOrReturnDefault("APP_BASE_URL", "http://localhost:8888")),
+
+			MailDriver: cast.ToString(getOrReturnDefault("MAIL_DRIVER", "log")),
+			MailFrom:   cast.ToString(getOrReturnDefault("MAIL_FROM", "no-reply@tender-bridge.local")),
+			MailDir:    cast.ToString(getOrReturnDefault("MAIL_DIR", "./mail")),
+
+			PasswordResetExpirationMinutes:   cast.ToInt(getOrReturnDefault("PASSWORD_RESET_EXPIRATION_MINUTES", 30)),
+			EmailVerificationExpirationHours: cast.ToInt(getOrReturnDefault("EMAIL_VERIFICATION_EXPIRATION_HOURS", 48)),
+			InvitationExpirationHours:        cast.ToInt(getOrReturnDefault("INVITATION_EXPIRATION_HOURS", 72)),
+
+			MFAIssuer: cast.ToString(getOrReturnDefault("MFA_ISSUER", "Tender Bridge")),
+
+			PasswordMinLength:        cast.ToInt(getOrReturnDefault("PASSWORD_MIN_LENGTH", 8)),
+			PasswordRequireLowercase: cast.ToBool(getOrReturnDefault("PASSWORD_REQUIRE_LOWERCASE", true)),
+			PasswordRequireUppercase: cast.ToBool(getOrReturnDefault("PASSWORD_REQUIRE_UPPERCASE", true)),
+			PasswordRequireDigit:     cast.ToBool(getOrReturnDefault("PASSWORD_REQUIRE_DIGIT", true)),
+			PasswordRequireSymbol:    cast.ToBool(getOrReturnDefault("PASSWORD_REQUIRE_SYMBOL", false)),
+			PasswordBreachedListFile: cast.ToString(getOrReturnDefault("PASSWORD_BREACHED_LIST_FILE", "")),
+
+			LoginMaxAttempts:      cast.ToInt(getOrReturnDefault("LOGIN_MAX_ATTEMPTS", 5)),
+			LoginMaxAttemptsPerIP: cast.ToInt(getOrReturnDefault("LOGIN_MAX_ATTEMPTS_PER_IP", 20)),
+			LoginLockoutMinutes:   cast.ToInt(getOrReturnDefault("LOGIN_LOCKOUT_MINUTES", 15)),
+
+			OIDCProvidersFile: cast.ToString(getOrReturnDefault("OIDC_PROVIDERS_FILE", "")),
+
+			TenderCloseIntervalSeconds: cast.ToInt(getOrReturnDefault("TENDER_CLOSE_INTERVAL_SECONDS", 60)),
+			BidSealKey:                 cast.ToString(getOrReturnDefault("BID_SEAL_KEY", defaultBidSealKey)),
+			ClarificationCutoffHours:   cast.ToInt(getOrReturnDefault("CLARIFICATION_CUTOFF_HOURS", 24)),
 		}
 	})
 
 	return instance
 }
 
+// Validate rejects settings the application must not start with
+func (c *Config) Validate() error {
+	if c.Environment != EnvironmentDevelopment && (c.BidSealKey == "" || c.BidSealKey == defaultBidSealKey) {
+		return errors.New("config: BID_SEAL_KEY must be set to a secret of its own outside development")
+	}
+
+	// unparsable values come through as 0 and would stop the scheduler
+	if c.TenderCloseIntervalSeconds <= 0 {
+		return errors.New("config: TENDER_CLOSE_INTERVAL_SECONDS must be a positive number")
+	}
+
+	return nil
+}
+
 func getOrReturnDefault(key string, defaultValue interface{}) interface{} {
 	value, exists := os.LookupEnv(key)
 	if exists {
@@ -74,3 +164,13 @@ func getOrReturnDefault(key string, defaultValue interface{}) interface{} {
 	}
 	return defaultValue
 }
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
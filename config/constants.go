@@ -7,6 +7,7 @@ const (
 	// token types
 	TokenTypeAccess  = "access"
 	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa"
 
 	DefaultPage  = "1"
 	DefaultLimit = "10"
@@ -15,11 +16,30 @@ const (
 	RoleClient     = "client"
 	RoleContractor = "contractor"
 
-	TenderStatusOpen    = "open"
-	TenderStatusClosed  = "closed"
-	TenderStatusAwarded = "awarded"
+	OrgRoleOwner   = "owner"
+	OrgRoleManager = "manager"
+	OrgRoleViewer  = "viewer"
+
+	TenderStatusDraft           = "draft"
+	TenderStatusPublished       = "published"
+	TenderStatusClosed          = "closed"
+	TenderStatusUnderEvaluation = "under_evaluation"
+	TenderStatusAwarded         = "awarded"
+	TenderStatusCancelled       = "cancelled"
 
 	BidStatusPending = "pending"
 	BidStatusAwarded = "awarded"
 	BidStatusClosed  = "closed"
+
+	TenderSortBudget    = "budget"
+	TenderSortDeadline  = "deadline"
+	TenderSortCreatedAt = "created_at"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	// audit log actions
+	AuditActionLoginLocked   = "login.locked"
+	AuditActionLoginUnlocked = "login.unlocked"
+	AuditActionBidsOpened    = "tender.bids_opened"
 )
@@ -9,6 +9,9 @@ import (
 	"golang.org/x/net/context"
 )
 
+// ErrNotFound is returned by Get when the key does not exist
+var ErrNotFound = redis.Nil
+
 type RedisCache struct {
 	client *redis.Client
 	ctx    context.Context
@@ -39,10 +42,73 @@ func (c *RedisCache) Set(key string, value any, ttl time.Duration) error {
 	return c.client.Set(c.ctx, key, data, ttl).Err()
 }
 
+// Take reads the value and removes it in one step, so that concurrent
+// callers cannot both consume it
+func (c *RedisCache) Take(key string, dest any) error {
+	data, err := c.client.GetDel(c.ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(data), dest)
+}
+
 func (c *RedisCache) Delete(key string) error {
 	return c.client.Del(c.ctx, key).Err()
 }
 
+// SetIfNotExists stores the value only when the key is absent and reports
+// whether it did so
+func (c *RedisCache) SetIfNotExists(key string, value any, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return c.client.SetNX(c.ctx, key, data, ttl).Result()
+}
+
+// Increment adds one to the counter stored at key and starts its expiry on
+// the first increment
+func (c *RedisCache) Increment(key string, ttl time.Duration) (int64, error) {
+	count, err := c.client.Incr(c.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		if err = c.client.Expire(c.ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func (c *RedisCache) Exists(key string) (bool, error) {
+	count, err := c.client.Exists(c.ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// TTL returns how long the key has left to live, or zero when it does not
+// exist or never expires
+func (c *RedisCache) TTL(key string) (time.Duration, error) {
+	ttl, err := c.client.TTL(c.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
 func (c *RedisCache) DeletePattern(pattern string) error {
 	iter := c.client.Scan(c.ctx, 0, pattern, 0).Iterator()
 	for iter.Next(c.ctx) {
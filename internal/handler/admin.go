@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const roleQuery = "role"
+
+var errSelfModification = errors.New("error: You cannot change your own account")
+
+type userListResponse struct {
+	Users      []models.User     `json:"users"`
+	Pagination models.Pagination `json:"pagination"`
+}
+
+// @Description Get Users
+// @Summary Get Users
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param limit query int64 true "limit" default(10)
+// @Param page  query int64 true "page" default(1)
+// @Param search query string false "search by username or email"
+// @Param role query string false "role"
+// @Success 200 {object} userListResponse
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/admin/users [get]
+// @Security ApiKeyAuth
+func (h *Handler) getAdminUsers(c *gin.Context) {
+	pagination, err := listPagination(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var filter models.UserFilter
+	filter.Limit = pagination.Limit
+	filter.Offset = pagination.Offset
+	filter.Search = c.Query(searchQuery)
+	filter.Role = c.Query(roleQuery)
+
+	users, total, err := h.service.User.GetUsers(filter)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	pagination.TotalCount = total
+	pagination.PageCount = (total + pagination.Limit - 1) / pagination.Limit
+
+	c.JSON(http.StatusOK, userListResponse{
+		Users:      users,
+		Pagination: pagination,
+	})
+}
+
+// @Description Get User
+// @Summary Get User
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} models.User
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) getAdminUser(c *gin.Context) {
+	userId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: User not found"))
+		return
+	}
+
+	user, err := h.service.User.GetUser(userId)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Description Change User Role
+// @Summary Change User Role
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Param role body models.UpdateUserRole true "new role"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id}/role [put]
+// @Security ApiKeyAuth
+func (h *Handler) updateUserRole(c *gin.Context) {
+	userId, ok := h.adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	var body models.UpdateUserRole
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.User.ChangeUserRole(userId, body.Role); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User role updated",
+	})
+}
+
+// @Description Suspend User
+// @Summary Suspend User
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id}/suspend [post]
+// @Security ApiKeyAuth
+func (h *Handler) suspendUser(c *gin.Context) {
+	userId, ok := h.adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.User.SuspendUser(userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User suspended",
+	})
+}
+
+// @Description Unsuspend User
+// @Summary Unsuspend User
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id}/unsuspend [post]
+// @Security ApiKeyAuth
+func (h *Handler) unsuspendUser(c *gin.Context) {
+	userId, ok := h.adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.User.UnsuspendUser(userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User unsuspended",
+	})
+}
+
+// @Description Lift a login lockout caused by repeated failed attempts
+// @Summary Unlock User
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id}/unlock [post]
+// @Security ApiKeyAuth
+func (h *Handler) unlockUser(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, ok := h.adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err = h.service.User.UnlockUser(userInfo.Id, userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User unlocked",
+	})
+}
+
+// @Description Permanently delete a user together with their tenders and bids
+// @Summary Delete User
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) deleteUser(c *gin.Context) {
+	userId, ok := h.adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.User.DeleteUser(userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User deleted successfully",
+	})
+}
+
+// adminTargetUser reads the user id path param and stops admins from
+// suspending, demoting or deleting themselves
+func (h *Handler) adminTargetUser(c *gin.Context) (uuid.UUID, bool) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return uuid.Nil, false
+	}
+
+	userId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: User not found"))
+		return uuid.Nil, false
+	}
+
+	if userId == userInfo.Id {
+		errorResponse(c, http.StatusBadRequest, errSelfModification)
+		return uuid.Nil, false
+	}
+
+	return userId, true
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Description Create an API key for integrations. Scopes are action names such as tender:create. The key is only returned in this response.
+// @Summary Create API Key
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param create body models.CreateAPIKey true "Create API key"
+// @Success 201 {object} models.CreatedAPIKey
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/api-keys [post]
+// @Security ApiKeyAuth
+func (h *Handler) createAPIKey(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.CreateAPIKey
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := h.service.APIKey.CreateAPIKey(userInfo.Subject(), body)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// @Description Get the API keys of the current user
+// @Summary Get API Keys
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.APIKey
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/api-keys [get]
+// @Security ApiKeyAuth
+func (h *Handler) getAPIKeys(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	keys, err := h.service.APIKey.GetAPIKeys(userInfo.Id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Description Revoke API Key
+// @Summary Revoke API Key
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "api key id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/api-keys/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: API key not found"))
+		return
+	}
+
+	if err = h.service.APIKey.RevokeAPIKey(userInfo.Id, id); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "API key revoked",
+	})
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"tender-bridge/config"
 	"tender-bridge/internal/models"
 	"tender-bridge/pkg/validator"
 
@@ -10,7 +11,13 @@ import (
 )
 
 type authResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type mfaChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
 }
 
 // @Description Register User
@@ -40,26 +47,27 @@ func (h *Handler) register(c *gin.Context) {
 		return
 	}
 
-	accessToken, _, err := h.service.Authorization.Register(body)
+	accessToken, refreshToken, err := h.service.Authorization.Register(body, getClient(c))
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, authResponse{
-		Token: accessToken.Token,
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
 	})
 }
 
 // Login
-// @Description Login User
+// @Description Login User. Accounts with two-factor authentication receive an mfaChallengeResponse instead of tokens and must continue with /login/mfa.
 // @Summary Login User
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param login body models.Login true "Login"
 // @Success 200 {object} authResponse
-// @Failure 400,404,500 {object} ErrorResponse
+// @Failure 400,404,429,500 {object} ErrorResponse
 // @Router /login [post]
 func (h *Handler) login(c *gin.Context) {
 	var body models.Login
@@ -79,13 +87,283 @@ func (h *Handler) login(c *gin.Context) {
 		return
 	}
 
-	accessToken, _, err := h.service.Authorization.Login(body)
+	accessToken, refreshToken, err := h.service.Authorization.Login(body, getClient(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	if accessToken.Type == config.TokenTypeMFA {
+		c.JSON(http.StatusOK, mfaChallengeResponse{
+			MFARequired: true,
+			MFAToken:    accessToken.Token,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse{
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
+	})
+}
+
+// @Description Complete a login with a TOTP or recovery code
+// @Summary Login MFA
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param login body models.LoginMFA true "MFA challenge"
+// @Success 200 {object} authResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /login/mfa [post]
+func (h *Handler) loginMFA(c *gin.Context) {
+	var body models.LoginMFA
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Authorization.VerifyMFA(body, getClient(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse{
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
+	})
+}
+
+// @Description Exchange a refresh token for a new access/refresh token pair
+// @Summary Refresh Tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} authResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /refresh [post]
+func (h *Handler) refresh(c *gin.Context) {
+	var body models.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Authorization.RefreshTokens(body.RefreshToken, getClient(c))
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, authResponse{
-		Token: accessToken.Token,
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
 	})
 }
+
+// @Description Revoke the current access token and, if given, the refresh token family
+// @Summary Logout
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param logout body models.Logout false "Logout"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /api/logout [post]
+// @Security ApiKeyAuth
+func (h *Handler) logout(c *gin.Context) {
+	var body models.Logout
+
+	// the body is optional, a bare logout only revokes the access token
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			errorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := h.service.Authorization.Logout(c.GetString(TokenCtx), body.RefreshToken); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// @Description Revoke every access and refresh token issued to a user
+// @Summary Revoke User Tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/users/{id}/revoke-tokens [post]
+// @Security ApiKeyAuth
+func (h *Handler) revokeUserTokens(c *gin.Context) {
+	userId, err := getUUIDParam(c, "id")
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: User not found"))
+		return
+	}
+
+	if err = h.service.Authorization.RevokeUserTokens(userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "User tokens revoked",
+	})
+}
+
+// @Description Send a password reset link to the given email
+// @Summary Request Password Reset
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordResetRequest true "Password reset request"
+// @Success 200 {object} BaseResponse
+// @Failure 400,500 {object} ErrorResponse
+// @Router /password/reset/request [post]
+func (h *Handler) requestPasswordReset(c *gin.Context) {
+	var body models.PasswordResetRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.Authorization.RequestPasswordReset(body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// @Description Set a new password using a reset token
+// @Summary Confirm Password Reset
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param confirm body models.PasswordResetConfirm true "Password reset confirmation"
+// @Success 200 {object} BaseResponse
+// @Failure 400,500 {object} ErrorResponse
+// @Router /password/reset/confirm [post]
+func (h *Handler) confirmPasswordReset(c *gin.Context) {
+	var body models.PasswordResetConfirm
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.Authorization.ResetPassword(body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Password has been reset",
+	})
+}
+
+// @Description Confirm the email address of an account
+// @Summary Verify Email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param verify body models.VerifyEmail true "Verification token"
+// @Success 200 {object} BaseResponse
+// @Failure 400,500 {object} ErrorResponse
+// @Router /verify-email [post]
+func (h *Handler) verifyEmail(c *gin.Context) {
+	var body models.VerifyEmail
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.Authorization.VerifyEmail(body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// @Description Send a new verification link to the current user
+// @Summary Resend Verification Email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/verify-email/resend [post]
+// @Security ApiKeyAuth
+func (h *Handler) resendVerificationEmail(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = h.service.Authorization.ResendVerificationEmail(userInfo.Id); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// @Description Public keys for verifying issued tokens, selected by the kid header
+// @Summary Get JWKS
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwks.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) getJWKS(c *gin.Context) {
+	// verifiers may cache the set, as long as a newly published key reaches
+	// them before it is used for signing
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.Authorization.PublicKeys())
+}
@@ -3,8 +3,8 @@ package handler
 import (
 	"errors"
 	"net/http"
-	"tender-bridge/config"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
 	"tender-bridge/pkg/validator"
 
 	"github.com/gin-gonic/gin"
@@ -34,11 +34,6 @@ func (h *Handler) submitBid(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleContractor {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
-		return
-	}
-
 	tenderId, err := getUUIDParam(c, "id")
 	if err != nil {
 		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
@@ -58,7 +53,7 @@ func (h *Handler) submitBid(c *gin.Context) {
 		return
 	}
 
-	bidId, err := h.service.Bid.SubmitBid(body)
+	bidId, err := h.service.Bid.SubmitBid(userInfo.Subject(), body)
 	if err != nil {
 		fromError(c, err)
 		return
@@ -75,7 +70,11 @@ func (h *Handler) submitBid(c *gin.Context) {
 // @Tags Bid
 // @Accept json
 // @Produce json
+// @Param search query string false "full text search in the bid comments, best matches first"
+// @Param cursor query string false "X-Next-Cursor or X-Prev-Cursor of an earlier page, replaces page"
 // @Success 200 {object} []models.Bid
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of the previous page"
 // @Failure 400,401,404,500 {object} ErrorResponse
 // @Router /api/contractor/bids [get]
 // @Security ApiKeyAuth
@@ -86,11 +85,6 @@ func (h *Handler) getContractorBids(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleContractor {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
-		return
-	}
-
 	pagination, err := listPagination(c)
 	if err != nil {
 		errorResponse(c, http.StatusBadRequest, err)
@@ -100,24 +94,34 @@ func (h *Handler) getContractorBids(c *gin.Context) {
 	var filter models.BidFilter
 	filter.Limit = pagination.Limit
 	filter.Offset = pagination.Offset
-	filter.ContractorId = userInfo.Id
+	filter.Search = c.Query(searchQuery)
 
-	bids, _, err := h.service.Bid.GetBids(filter)
+	if filter.Cursor, err = getCursorQuery(c); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	bids, cursors, err := h.service.Bid.GetMemberBids(userInfo.Subject(), filter)
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
+	setPageCursors(c, cursors)
 	c.JSON(http.StatusOK, bids)
 }
 
-// @Description Get Client Tender Bids
+// @Description Get Client Tender Bids. The bids of a sealed tender are withheld until it closes, until then only their count is returned as models.SealedBids.
 // @Summary Get Client Tender Bids
 // @Tags Bid
 // @Accept json
 // @Produce json
 // @Param id path string true "tender id"
+// @Param search query string false "full text search in the bid comments, best matches first"
+// @Param cursor query string false "X-Next-Cursor or X-Prev-Cursor of an earlier page, replaces page"
 // @Success 200 {object} []models.Bid
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of the previous page"
 // @Failure 400,401,404,500 {object} ErrorResponse
 // @Router /api/client/tenders/{id}/bids [get]
 // @Security ApiKeyAuth
@@ -128,11 +132,6 @@ func (h *Handler) getClientTenderBids(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleClient {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
-		return
-	}
-
 	tenderId, err := getUUIDParam(c, "id")
 	if err != nil {
 		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found or access denied"))
@@ -149,13 +148,25 @@ func (h *Handler) getClientTenderBids(c *gin.Context) {
 	filter.Limit = pagination.Limit
 	filter.Offset = pagination.Offset
 	filter.TenderId = tenderId
+	filter.Search = c.Query(searchQuery)
 
-	bids, _, err := h.service.Bid.GetBids(filter)
+	if filter.Cursor, err = getCursorQuery(c); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	bids, cursors, sealed, err := h.service.Bid.GetTenderBids(userInfo.Subject(), filter)
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
+	if sealed != nil {
+		c.JSON(http.StatusOK, sealed)
+		return
+	}
+
+	setPageCursors(c, cursors)
 	c.JSON(http.StatusOK, bids)
 }
 
@@ -177,11 +188,6 @@ func (h *Handler) awardBid(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleClient {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
-		return
-	}
-
 	tenderId, err := getUUIDParam(c, "id")
 	if err != nil {
 		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found or access denied"))
@@ -194,7 +200,7 @@ func (h *Handler) awardBid(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Bid.AwardBid(userInfo.Id, tenderId, bidId)
+	err = h.service.Bid.AwardBid(userInfo.Subject(), tenderId, bidId)
 	if err != nil {
 		fromError(c, err)
 		return
@@ -212,8 +218,36 @@ func (h *Handler) deleteContractorBid(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleContractor {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
+	bidId, err := getUUIDParam(c, "id")
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Bid not found or access denied"))
+		return
+	}
+
+	if err = h.service.Bid.DeleteContractorBid(userInfo.Subject(), bidId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Bid deleted successfully",
+	})
+}
+
+// @Description Acknowledge the amendments made to the tender of the bid. Bids that have not acknowledged the latest material amendment cannot be awarded.
+// @Summary Acknowledge Tender Amendment
+// @Tags Bid
+// @Accept json
+// @Produce json
+// @Param id path string true "bid id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/contractor/bids/{id}/acknowledge [post]
+// @Security ApiKeyAuth
+func (h *Handler) acknowledgeAmendment(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -223,13 +257,13 @@ func (h *Handler) deleteContractorBid(c *gin.Context) {
 		return
 	}
 
-	if err = h.service.Bid.DeleteContractorBid(userInfo.Id, bidId); err != nil {
+	if err = h.service.Bid.AcknowledgeAmendment(userInfo.Subject(), bidId); err != nil {
 		fromError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, BaseResponse{
-		Message: "Bid deleted successfully",
+		Message: "Amendment acknowledged",
 	})
 }
 
@@ -241,7 +275,11 @@ func (h *Handler) deleteContractorBid(c *gin.Context) {
 // @Param id path string true "user id"
 // @Param page query string true "page" Default(1)
 // @Param limit query string true "limit" Default(10)
+// @Param search query string false "full text search in the bid comments, best matches first"
+// @Param cursor query string false "X-Next-Cursor or X-Prev-Cursor of an earlier page, replaces page"
 // @Success 200 {object} []models.Bid
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of the previous page"
 // @Failure 400,401,404,500 {object} ErrorResponse
 // @Router /api/users/{id}/bids [get]
 // @Security ApiKeyAuth
@@ -263,7 +301,7 @@ func (h *Handler) getUserBids(c *gin.Context) {
 		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	if userInfo.Id != userId {
+	if !policy.CanAccessUser(userInfo.Subject(), policy.UserActivityRead, userId) {
 		errorResponse(c, http.StatusForbidden, errors.New("access denied"))
 		return
 	}
@@ -272,12 +310,19 @@ func (h *Handler) getUserBids(c *gin.Context) {
 	bidFilter.Limit = pagination.Limit
 	bidFilter.Offset = pagination.Offset
 	bidFilter.ContractorId = userId
+	bidFilter.Search = c.Query(searchQuery)
+
+	if bidFilter.Cursor, err = getCursorQuery(c); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
 
-	bids, _, err := h.service.Bid.GetBids(bidFilter)
+	bids, cursors, err := h.service.Bid.GetBids(bidFilter)
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
+	setPageCursors(c, cursors)
 	c.JSON(http.StatusOK, bids)
 }
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type createCategoryResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+// @Description Get the category tree
+// @Summary Get Categories
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.Category
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/categories [get]
+// @Security ApiKeyAuth
+func (h *Handler) getCategories(c *gin.Context) {
+	categories, err := h.service.Category.GetCategories()
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Description Create a category, optionally under a parent category. The slug is derived from the name when left out.
+// @Summary Create Category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param create body models.CreateCategory true "Create category"
+// @Success 201 {object} createCategoryResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/categories [post]
+// @Security ApiKeyAuth
+func (h *Handler) createCategory(c *gin.Context) {
+	var body models.CreateCategory
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.service.Category.CreateCategory(body)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createCategoryResponse{
+		Id: id,
+	})
+}
+
+// @Description Delete a category without subcategories. Its tenders are left without a category.
+// @Summary Delete Category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path string true "category id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/admin/categories/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) deleteCategory(c *gin.Context) {
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Category not found"))
+		return
+	}
+
+	if err = h.service.Category.DeleteCategory(id); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Category deleted",
+	})
+}
+
+// @Description Get the categories the current user follows
+// @Summary Get Subscriptions
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.Category
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/contractor/subscriptions [get]
+// @Security ApiKeyAuth
+func (h *Handler) getSubscriptions(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	categories, err := h.service.Category.GetSubscriptions(userInfo.Id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Description Follow a category to be notified when a tender is published in it or in one of its subcategories
+// @Summary Subscribe To Category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path string true "category id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/contractor/subscriptions/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) subscribe(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	categoryId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Category not found"))
+		return
+	}
+
+	if err = h.service.Category.Subscribe(userInfo.Id, categoryId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Subscribed",
+	})
+}
+
+// @Description Stop following a category
+// @Summary Unsubscribe From Category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path string true "category id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/contractor/subscriptions/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) unsubscribe(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	categoryId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Category not found"))
+		return
+	}
+
+	if err = h.service.Category.Unsubscribe(userInfo.Id, categoryId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Unsubscribed",
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type askQuestionResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+// @Description Ask a question about a published tender. Answers are published to every bidder without saying who asked.
+// @Summary Ask Question
+// @Tags Clarification
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Param create body models.CreateClarification true "Ask question"
+// @Success 201 {object} askQuestionResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/contractor/tenders/{id}/clarifications [post]
+// @Security ApiKeyAuth
+func (h *Handler) askQuestion(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tenderId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	var body models.CreateClarification
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	body.TenderId = tenderId
+
+	id, err := h.service.Clarification.AskQuestion(userInfo.Subject(), body)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, askQuestionResponse{
+		Id: id,
+	})
+}
+
+// @Description Get the clarifications of a tender. Owners of the tender see every question and who asked it, others see the answered questions and their own.
+// @Summary Get Clarifications
+// @Tags Clarification
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Success 200 {object} []models.Clarification
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/client/tenders/{id}/clarifications [get]
+// @Security ApiKeyAuth
+func (h *Handler) getClarifications(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tenderId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	clarifications, err := h.service.Clarification.GetClarifications(userInfo.Subject(), tenderId)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clarifications)
+}
+
+// @Description Answer a question. The answer is published to every bidder.
+// @Summary Answer Question
+// @Tags Clarification
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Param clarificationId path string true "clarification id"
+// @Param answer body models.AnswerClarification true "Answer question"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/client/tenders/{id}/clarifications/{clarificationId}/answer [post]
+// @Security ApiKeyAuth
+func (h *Handler) answerQuestion(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tenderId, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	clarificationId, err := getUUIDParam(c, "clarificationId")
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Clarification not found"))
+		return
+	}
+
+	var body models.AnswerClarification
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	body.Id = clarificationId
+	body.TenderId = tenderId
+
+	if err = h.service.Clarification.AnswerQuestion(userInfo.Subject(), body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Answer published",
+	})
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"tender-bridge/config"
 	"tender-bridge/docs"
+	"tender-bridge/internal/policy"
 	"tender-bridge/internal/service"
 	"tender-bridge/internal/ws"
 	"tender-bridge/pkg/logger"
@@ -28,6 +29,12 @@ func NewHandler(service *service.Service, loggers *logger.Logger) *Handler {
 func (h *Handler) InitRoutes(cfg *config.Config) *gin.Engine {
 	router := gin.Default()
 
+	// client IPs key the login lockout, so X-Forwarded-For is only believed
+	// when it comes from a configured proxy
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		h.logger.Fatal(err)
+	}
+
 	// Setup Swagger documentation
 	h.setupSwagger(router)
 
@@ -39,8 +46,12 @@ func (h *Handler) InitRoutes(cfg *config.Config) *gin.Engine {
 
 	// Protected API routes
 	api := router.Group("/api", h.userIdentity)
+	h.setupAuthRoutes(api)
+	h.setupAdminRoutes(api)
+	h.setupOrganizationRoutes(api)
 	h.setupClientRoutes(api)
 	h.setupContractorRoutes(api)
+	h.setupCategoryRoutes(api)
 
 	// WebSocket route
 	router.GET("/ws", func(c *gin.Context) {
@@ -64,21 +75,97 @@ func (h *Handler) setupSwagger(router *gin.Engine) {
 func (h *Handler) setupPublicRoutes(router *gin.Engine) {
 	router.POST("/register", h.register)
 	router.POST("/login", h.login)
+	router.POST("/login/mfa", h.loginMFA)
+	router.POST("/refresh", h.refresh)
+	router.POST("/password/reset/request", h.requestPasswordReset)
+	router.POST("/password/reset/confirm", h.confirmPasswordReset)
+	router.POST("/verify-email", h.verifyEmail)
+	router.GET("/.well-known/jwks.json", h.getJWKS)
+
+	oidc := router.Group("/oidc")
+	{
+		oidc.GET("/providers", h.getOIDCProviders)
+		oidc.GET("/:provider/login", h.oidcLogin)
+		oidc.GET("/:provider/callback", h.oidcCallback)
+	}
+}
+
+func (h *Handler) setupAuthRoutes(api *gin.RouterGroup) {
+	api.GET("/me", h.getMe)
+
+	account := api.Group("", h.sessionOnly)
+	account.PATCH("/me", h.updateMe)
+	account.POST("/me/password", h.changePassword)
+	account.POST("/logout", h.logout)
+	account.POST("/verify-email/resend", h.resendVerificationEmail)
+
+	mfa := account.Group("/mfa/totp")
+	{
+		mfa.POST("/enroll", h.enrollTOTP)
+		mfa.POST("/confirm", h.confirmTOTP)
+		mfa.POST("/disable", h.disableTOTP)
+	}
+
+	apiKeys := account.Group("/api-keys")
+	{
+		apiKeys.POST("", h.createAPIKey)
+		apiKeys.GET("", h.getAPIKeys)
+		apiKeys.DELETE("/:id", h.revokeAPIKey)
+	}
+
+	sessions := account.Group("/sessions")
+	{
+		sessions.GET("", h.getSessions)
+		sessions.DELETE("/:id", h.revokeSession)
+	}
+}
+
+func (h *Handler) setupAdminRoutes(api *gin.RouterGroup) {
+	adminUsers := api.Group("/admin/users", h.authorize(policy.UserManage))
+	{
+		adminUsers.GET("", h.getAdminUsers)
+		adminUsers.GET("/:id", h.getAdminUser)
+		adminUsers.PUT("/:id/role", h.updateUserRole)
+		adminUsers.POST("/:id/suspend", h.suspendUser)
+		adminUsers.POST("/:id/unsuspend", h.unsuspendUser)
+		adminUsers.POST("/:id/unlock", h.unlockUser)
+		adminUsers.POST("/:id/revoke-tokens", h.revokeUserTokens)
+		adminUsers.DELETE("/:id", h.deleteUser)
+	}
+}
+
+func (h *Handler) setupOrganizationRoutes(api *gin.RouterGroup) {
+	organizations := api.Group("/organizations")
+	{
+		organizations.POST("", h.sessionOnly, h.createOrganization)
+		organizations.GET("", h.authorize(policy.OrganizationRead), h.getOrganizations)
+		organizations.POST("/invitations/accept", h.sessionOnly, h.acceptOrganizationInvitation)
+		organizations.GET("/:id", h.authorize(policy.OrganizationRead), h.getOrganization)
+		organizations.GET("/:id/members", h.authorize(policy.OrganizationRead), h.getOrganizationMembers)
+		organizations.PUT("/:id/members/:userId", h.authorize(policy.OrganizationManageMembers), h.updateOrganizationMember)
+		organizations.DELETE("/:id/members/:userId", h.authorize(policy.OrganizationRead), h.removeOrganizationMember)
+		organizations.POST("/:id/invitations", h.authorize(policy.OrganizationInvite), h.inviteOrganizationMember)
+	}
 }
 
 func (h *Handler) setupClientRoutes(api *gin.RouterGroup) {
 	clientTenders := api.Group("/client/tenders")
 	{
-		clientTenders.POST("", h.createTender)
-		clientTenders.GET("", h.getTenders)
-		clientTenders.GET("/:id", h.getTender)
-		clientTenders.PUT("/:id", h.updateTenderStatus)
-		clientTenders.DELETE("/:id", h.deleteTender)
-		clientTenders.GET("/:id/bids", h.getClientTenderBids)
-		clientTenders.POST("/:id/award/:bidId", h.awardBid)
+		clientTenders.POST("", h.authorize(policy.TenderCreate), h.createTender)
+		clientTenders.GET("", h.authorize(policy.TenderList), h.getTenders)
+		clientTenders.GET("/:id", h.authorize(policy.TenderRead), h.getTender)
+		clientTenders.PUT("/:id", h.authorize(policy.TenderUpdate), h.updateTenderStatus)
+		clientTenders.DELETE("/:id", h.authorize(policy.TenderDelete), h.deleteTender)
+		clientTenders.GET("/:id/bids", h.authorize(policy.TenderListBids), h.getClientTenderBids)
+		clientTenders.GET("/:id/history", h.authorize(policy.TenderHistory), h.getTenderHistory)
+		clientTenders.POST("/:id/amendments", h.authorize(policy.TenderUpdate), h.amendTender)
+		clientTenders.GET("/:id/revisions", h.authorize(policy.TenderRead), h.getTenderRevisions)
+		clientTenders.GET("/:id/clarifications", h.authorize(policy.TenderRead), h.getClarifications)
+		clientTenders.POST("/:id/clarifications/:clarificationId/answer", h.authorize(policy.ClarificationAnswer), h.answerQuestion)
+		clientTenders.POST("/:id/award/:bidId", h.authorize(policy.TenderAward), h.awardBid)
 	}
 
-	users := api.Group("/users")
+	users := api.Group("/users", h.authorize(policy.UserActivityRead))
 	{
 		users.GET("/:id/tenders", h.getUserTenders)
 		users.GET("/:id/bids", h.getUserBids)
@@ -88,9 +175,29 @@ func (h *Handler) setupClientRoutes(api *gin.RouterGroup) {
 func (h *Handler) setupContractorRoutes(api *gin.RouterGroup) {
 	contractorBids := api.Group("/contractor/tenders/:id/bid")
 	{
-		contractorBids.POST("", rateLimitMiddleware(5, time.Minute), h.submitBid)
+		contractorBids.POST("", h.authorize(policy.BidSubmit), rateLimitMiddleware("bid", 5, time.Minute), h.submitBid)
+	}
+
+	api.POST("/contractor/tenders/:id/clarifications", h.authorize(policy.ClarificationAsk), rateLimitMiddleware("clarification", 5, time.Minute), h.askQuestion)
+
+	api.GET("/contractor/bids", h.authorize(policy.BidListOwn), h.getContractorBids)
+	api.DELETE("/contractor/bids/:id", h.authorize(policy.BidDelete), h.deleteContractorBid)
+	api.POST("/contractor/bids/:id/acknowledge", h.authorize(policy.BidAcknowledge), h.acknowledgeAmendment)
+}
+
+func (h *Handler) setupCategoryRoutes(api *gin.RouterGroup) {
+	api.GET("/categories", h.authorize(policy.TenderList), h.getCategories)
+
+	adminCategories := api.Group("/admin/categories", h.authorize(policy.CategoryManage))
+	{
+		adminCategories.POST("", h.createCategory)
+		adminCategories.DELETE("/:id", h.deleteCategory)
 	}
 
-	api.GET("/contractor/bids", h.getContractorBids)
-	api.DELETE("/contractor/bids/:id", h.deleteContractorBid)
+	subscriptions := api.Group("/contractor/subscriptions", h.authorize(policy.CategorySubscribe))
+	{
+		subscriptions.GET("", h.getSubscriptions)
+		subscriptions.PUT("/:id", h.subscribe)
+		subscriptions.DELETE("/:id", h.unsubscribe)
+	}
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strconv"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
+	"tender-bridge/pkg/helper"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,6 +16,8 @@ import (
 var (
 	errInvalidUserId   = errors.New("invalid user id")
 	errInvalidUserRole = errors.New("invalid user role")
+
+	errPermissionDenied = errors.New("permission denied")
 )
 
 type UserInfo struct {
@@ -20,6 +25,14 @@ type UserInfo struct {
 	Role string
 }
 
+// Subject returns the caller as seen by the policy package
+func (u UserInfo) Subject() policy.Subject {
+	return policy.Subject{
+		Id:   u.Id,
+		Role: u.Role,
+	}
+}
+
 func getUserInfo(ctx *gin.Context) (UserInfo, error) {
 	var userInfo UserInfo
 
@@ -46,6 +59,43 @@ func getUserInfo(ctx *gin.Context) (UserInfo, error) {
 	return userInfo, nil
 }
 
+// getSessionId returns the session of the access token the request was made
+// with, or nil for API keys and tokens issued before sessions existed
+func getSessionId(ctx *gin.Context) *uuid.UUID {
+	value, ok := ctx.Get(SessionCtx)
+	if !ok {
+		return nil
+	}
+
+	sessionId, ok := value.(uuid.UUID)
+	if !ok {
+		return nil
+	}
+
+	return &sessionId
+}
+
+// getClient describes the device the request came from
+func getClient(ctx *gin.Context) models.Client {
+	return models.Client{
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// getScopes returns the scopes of the API key the request was made with. The
+// second result is false for requests authenticated with a JWT.
+func getScopes(ctx *gin.Context) ([]string, bool) {
+	value, ok := ctx.Get(ScopesCtx)
+	if !ok {
+		return nil, false
+	}
+
+	// an unexpected value grants nothing rather than everything
+	scopes, _ := value.([]string)
+	return scopes, true
+}
+
 func listPagination(c *gin.Context) (models.Pagination, error) {
 	page, err := getPageQuery(c)
 	if err != nil {
@@ -66,6 +116,31 @@ func listPagination(c *gin.Context) (models.Pagination, error) {
 	}, nil
 }
 
+// getCursorQuery returns nil when the list is paged by page number
+func getCursorQuery(c *gin.Context) (*models.Cursor, error) {
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, nil
+	}
+
+	var cursor models.Cursor
+	if err := helper.DecodeCursor(value, &cursor); err != nil {
+		return nil, errors.New("invalid cursor parameter")
+	}
+	return &cursor, nil
+}
+
+// setPageCursors sends the cursors of the pages around a list page in
+// headers, so the body stays the plain list page based clients expect
+func setPageCursors(c *gin.Context, cursors models.PageCursors) {
+	if cursors.Next != "" {
+		c.Header("X-Next-Cursor", cursors.Next)
+	}
+	if cursors.Prev != "" {
+		c.Header("X-Prev-Cursor", cursors.Prev)
+	}
+}
+
 func getPageQuery(c *gin.Context) (int, error) {
 	pageStr := c.DefaultQuery("page", "1")
 	page, err := strconv.Atoi(pageStr)
@@ -84,6 +159,36 @@ func getLimitQuery(c *gin.Context) (int, error) {
 	return limit, nil
 }
 
+// getInt64Query returns nil when the query parameter is not set
+func getInt64Query(c *gin.Context, param string) (*int64, error) {
+	valueStr := c.Query(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter", param)
+	}
+	return &value, nil
+}
+
+// getTimeQuery accepts RFC 3339 timestamps and plain dates, and returns nil
+// when the query parameter is not set
+func getTimeQuery(c *gin.Context, param string) (*time.Time, error) {
+	valueStr := c.Query(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if value, err := time.Parse(layout, valueStr); err == nil {
+			return &value, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s parameter", param)
+}
+
 func calculatePagination(page, limit int) (int, int) {
 	if page < 1 {
 		page = 1
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// @Description Start TOTP enrollment and get the secret for an authenticator app
+// @Summary Enroll TOTP
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.TOTPEnrollment
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/mfa/totp/enroll [post]
+// @Security ApiKeyAuth
+func (h *Handler) enrollTOTP(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	enrollment, err := h.service.MFA.EnrollTOTP(userInfo.Id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// @Description Confirm TOTP enrollment with a code from the authenticator app
+// @Summary Confirm TOTP
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param confirm body models.MFACode true "TOTP code"
+// @Success 200 {object} recoveryCodesResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /api/mfa/totp/confirm [post]
+// @Security ApiKeyAuth
+func (h *Handler) confirmTOTP(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.MFACode
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	recoveryCodes, err := h.service.MFA.ConfirmTOTP(userInfo.Id, body.Code)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// @Description Turn off two-factor authentication with a TOTP or recovery code
+// @Summary Disable TOTP
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param disable body models.MFACode true "TOTP or recovery code"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /api/mfa/totp/disable [post]
+// @Security ApiKeyAuth
+func (h *Handler) disableTOTP(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.MFACode
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = h.service.MFA.DisableTOTP(userInfo.Id, body.Code); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
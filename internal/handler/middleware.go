@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"tender-bridge/config"
+	"tender-bridge/internal/policy"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +19,12 @@ const (
 	AuthorizationHeader = "Authorization"
 	UserCtx             = "user_id"
 	RoleCtx             = "role"
+	TokenCtx            = "token"
+	ScopesCtx           = "scopes"
+	SessionCtx          = "session_id"
+
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -28,7 +36,7 @@ func (h *Handler) userIdentity(c *gin.Context) {
 	}
 
 	headerParts := strings.Split(header, " ")
-	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+	if len(headerParts) != 2 || (headerParts[0] != bearerScheme && headerParts[0] != apiKeyScheme) {
 		errorResponse(c, http.StatusUnauthorized, errors.New("invalid auth header"))
 		c.Abort()
 		return
@@ -40,6 +48,11 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
+	if headerParts[0] == apiKeyScheme {
+		h.apiKeyIdentity(c, headerParts[1])
+		return
+	}
+
 	claims, err := h.service.Authorization.ParseToken(headerParts[1])
 	if err != nil {
 		errorResponse(c, http.StatusUnauthorized, err)
@@ -53,8 +66,81 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
+	revoked, err := h.service.Authorization.IsTokenRevoked(claims)
+	if err != nil {
+		h.logger.Error(err)
+		errorResponse(c, http.StatusInternalServerError, errors.New("failed to verify token"))
+		c.Abort()
+		return
+	}
+
+	if revoked {
+		errorResponse(c, http.StatusUnauthorized, errors.New("token has been revoked"))
+		c.Abort()
+		return
+	}
+
 	c.Set(UserCtx, claims.UserId)
 	c.Set(RoleCtx, claims.Role)
+	c.Set(TokenCtx, headerParts[1])
+	if claims.SessionId != nil {
+		c.Set(SessionCtx, *claims.SessionId)
+	}
+	c.Next()
+}
+
+// apiKeyIdentity authenticates machine clients. Their scopes are stored in
+// the context and limit what authorize lets through.
+func (h *Handler) apiKeyIdentity(c *gin.Context, key string) {
+	user, scopes, err := h.service.APIKey.AuthenticateAPIKey(key)
+	if err != nil {
+		fromError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set(UserCtx, user.Id)
+	c.Set(RoleCtx, user.Role)
+	c.Set(ScopesCtx, scopes)
+	c.Next()
+}
+
+// authorize rejects callers whose role, or API key scopes, do not grant the
+// action. Checks that depend on the resource itself are done by the service.
+func (h *Handler) authorize(action policy.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInfo, err := getUserInfo(c)
+		if err != nil {
+			errorResponse(c, http.StatusUnauthorized, err)
+			c.Abort()
+			return
+		}
+
+		if !policy.Can(userInfo.Role, action) {
+			errorResponse(c, http.StatusForbidden, errPermissionDenied)
+			c.Abort()
+			return
+		}
+
+		if scopes, ok := getScopes(c); ok && !slices.Contains(scopes, string(action)) {
+			errorResponse(c, http.StatusForbidden, errors.New("error: API key scope does not allow this action"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sessionOnly keeps API keys away from account management, so a leaked key
+// cannot be used to mint new keys or change how the user signs in
+func (h *Handler) sessionOnly(c *gin.Context) {
+	if _, ok := getScopes(c); ok {
+		errorResponse(c, http.StatusForbidden, errors.New("error: This endpoint cannot be used with an API key"))
+		c.Abort()
+		return
+	}
+
 	c.Next()
 }
 
@@ -66,6 +152,7 @@ func corsMiddleware() gin.HandlerFunc {
 		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With,Access-Control-Request-Method, Access-Control-Request-Headers")
 		ctx.Header("Access-Control-Max-Age", "3600")
 		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH, HEAD")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor, X-Prev-Cursor")
 		if ctx.Request.Method == "OPTIONS" {
 			ctx.AbortWithStatus(204)
 			return
@@ -86,8 +173,10 @@ func init() {
 	})
 }
 
-// RateLimitMiddleware enforces rate limits for a specific endpoint
-func rateLimitMiddleware(limit int, duration time.Duration) gin.HandlerFunc {
+// RateLimitMiddleware enforces rate limits for a specific endpoint. Each
+// bucket is counted separately per user, so endpoints with different
+// buckets do not use up each other's quota.
+func rateLimitMiddleware(bucket string, limit int, duration time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInfo, err := getUserInfo(c)
 		if err != nil {
@@ -103,7 +192,7 @@ func rateLimitMiddleware(limit int, duration time.Duration) gin.HandlerFunc {
 		}
 
 		ctx := context.Background()
-		key := "rate_limit:" + userInfo.Id.String()
+		key := "rate_limit:" + bucket + ":" + userInfo.Id.String()
 
 		// Increment the count and set expiration if key doesn't exist
 		count, err := redisClient.Incr(ctx, key).Result()
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type oidcProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// @Description Identity providers that can be used to sign in
+// @Summary Get OIDC Providers
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} oidcProvidersResponse
+// @Router /oidc/providers [get]
+func (h *Handler) getOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, oidcProvidersResponse{
+		Providers: h.service.OIDC.GetProviders(),
+	})
+}
+
+// @Description Redirect to the identity provider to sign in
+// @Summary OIDC Login
+// @Tags Auth
+// @Param provider path string true "provider name"
+// @Success 302
+// @Failure 404,500 {object} ErrorResponse
+// @Router /oidc/{provider}/login [get]
+func (h *Handler) oidcLogin(c *gin.Context) {
+	url, err := h.service.OIDC.LoginURL(c.Param("provider"))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// @Description Complete sign-in after the identity provider redirects back
+// @Summary OIDC Callback
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "provider name"
+// @Param code query string true "authorization code"
+// @Param state query string true "state"
+// @Success 200 {object} authResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /oidc/{provider}/callback [get]
+func (h *Handler) oidcCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		errorResponse(c, http.StatusBadRequest, errors.New("error: Identity provider returned "+providerErr))
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		errorResponse(c, http.StatusBadRequest, errors.New("error: Missing code or state"))
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.OIDC.Callback(c.Param("provider"), code, state, getClient(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse{
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
+	})
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errOrganizationNotFound = errors.New("error: Organization not found or access denied")
+
+type createOrganizationResponse struct {
+	Id   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// @Description Create an organization owned by the current user
+// @Summary Create Organization
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param create body models.CreateOrganization true "Create organization"
+// @Success 201 {object} createOrganizationResponse
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /api/organizations [post]
+// @Security ApiKeyAuth
+func (h *Handler) createOrganization(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.CreateOrganization
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.service.Organization.CreateOrganization(userInfo.Id, body)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createOrganizationResponse{
+		Id:   id,
+		Name: body.Name,
+	})
+}
+
+// @Description Get the organizations of the current user with their role in each
+// @Summary Get Organizations
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.Organization
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /api/organizations [get]
+// @Security ApiKeyAuth
+func (h *Handler) getOrganizations(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	organizations, err := h.service.Organization.GetOrganizations(userInfo.Id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// @Description Get Organization
+// @Summary Get Organization
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param id path string true "organization id"
+// @Success 200 {object} models.Organization
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/organizations/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) getOrganization(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errOrganizationNotFound)
+		return
+	}
+
+	organization, err := h.service.Organization.GetOrganization(userInfo.Subject(), id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// @Description Get Organization Members
+// @Summary Get Organization Members
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param id path string true "organization id"
+// @Success 200 {object} []models.OrganizationMember
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/organizations/{id}/members [get]
+// @Security ApiKeyAuth
+func (h *Handler) getOrganizationMembers(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errOrganizationNotFound)
+		return
+	}
+
+	members, err := h.service.Organization.GetMembers(userInfo.Subject(), id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// @Description Change the role of a member. Only owners can do this.
+// @Summary Update Member Role
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param id path string true "organization id"
+// @Param userId path string true "user id"
+// @Param update body models.UpdateMemberRole true "new role: owner, manager or viewer"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/organizations/{id}/members/{userId} [put]
+// @Security ApiKeyAuth
+func (h *Handler) updateOrganizationMember(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errOrganizationNotFound)
+		return
+	}
+
+	userId, err := getUUIDParam(c, "userId")
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Member not found"))
+		return
+	}
+
+	var body models.UpdateMemberRole
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = h.service.Organization.UpdateMemberRole(userInfo.Subject(), id, userId, body.Role); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Member role updated",
+	})
+}
+
+// @Description Remove a member. Owners can remove anyone, other members can only remove themselves.
+// @Summary Remove Member
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param id path string true "organization id"
+// @Param userId path string true "user id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/organizations/{id}/members/{userId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) removeOrganizationMember(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errOrganizationNotFound)
+		return
+	}
+
+	userId, err := getUUIDParam(c, "userId")
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Member not found"))
+		return
+	}
+
+	if err = h.service.Organization.RemoveMember(userInfo.Subject(), id, userId); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Member removed",
+	})
+}
+
+// @Description Invite someone by email to join the organization
+// @Summary Invite Member
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param id path string true "organization id"
+// @Param invite body models.InviteMember true "email and role: owner, manager or viewer"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/organizations/{id}/invitations [post]
+// @Security ApiKeyAuth
+func (h *Handler) inviteOrganizationMember(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errOrganizationNotFound)
+		return
+	}
+
+	var body models.InviteMember
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = h.service.Organization.InviteMember(userInfo.Subject(), id, body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Invitation sent",
+	})
+}
+
+// @Description Accept an organization invitation sent to the current user's email
+// @Summary Accept Invitation
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param accept body models.AcceptInvitation true "invitation token"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/organizations/invitations/accept [post]
+// @Security ApiKeyAuth
+func (h *Handler) acceptOrganizationInvitation(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.AcceptInvitation
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = h.service.Organization.AcceptInvitation(userInfo.Id, body); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Invitation accepted",
+	})
+}
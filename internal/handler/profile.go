@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type profileResponse struct {
+	User         models.User `json:"user"`
+	Token        string      `json:"token,omitempty"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+}
+
+// @Description Get the current user
+// @Summary Get Me
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.User
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/me [get]
+// @Security ApiKeyAuth
+func (h *Handler) getMe(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.service.Profile.GetProfile(userInfo.Id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Description Change the username or email of the current user. A change signs out all other sessions and returns new tokens; a new email must be verified again.
+// @Summary Update Me
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param profile body models.UpdateProfile true "Profile"
+// @Success 200 {object} profileResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/me [patch]
+// @Security ApiKeyAuth
+func (h *Handler) updateMe(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.UpdateProfile
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.service.Profile.UpdateProfile(userInfo.Id, body, getClient(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	response := profileResponse{User: user}
+	if accessToken != nil {
+		response.Token = accessToken.Token
+		response.RefreshToken = refreshToken.Token
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Description Change the password of the current user. All sessions and API keys are revoked and new tokens are returned.
+// @Summary Change Password
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param password body models.ChangePassword true "Change password"
+// @Success 200 {object} authResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/me/password [post]
+// @Security ApiKeyAuth
+func (h *Handler) changePassword(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.ChangePassword
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = validator.ValidatePayloads(body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Profile.ChangePassword(userInfo.Id, body, getClient(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse{
+		Token:        accessToken.Token,
+		RefreshToken: refreshToken.Token,
+	})
+}
@@ -40,6 +40,12 @@ func fromError(c *gin.Context, serviceError error) {
 		errorResponse(c, http.StatusBadRequest, errors.New(err))
 	case codes.Unauthenticated:
 		errorResponse(c, http.StatusUnauthorized, errors.New(err))
+	case codes.PermissionDenied:
+		errorResponse(c, http.StatusForbidden, errors.New(err))
+	case codes.ResourceExhausted:
+		errorResponse(c, http.StatusTooManyRequests, errors.New(err))
+	case codes.Aborted:
+		errorResponse(c, http.StatusConflict, errors.New(err))
 	default:
 		errorResponse(c, http.StatusInternalServerError, errors.New(err))
 	}
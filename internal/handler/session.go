@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Description Get the devices the current user is signed in on. The session of the calling token is marked as current.
+// @Summary Get Sessions
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.Session
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /api/sessions [get]
+// @Security ApiKeyAuth
+func (h *Handler) getSessions(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sessions, err := h.service.Session.GetSessions(userInfo.Id, getSessionId(c))
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Description Sign out a device. Its refresh token stops working and its access tokens are rejected.
+// @Summary Revoke Session
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Param id path string true "session id"
+// @Success 200 {object} BaseResponse
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /api/sessions/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) revokeSession(c *gin.Context) {
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Session not found"))
+		return
+	}
+
+	if err = h.service.Session.RevokeSession(userInfo.Id, id); err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BaseResponse{
+		Message: "Session revoked",
+	})
+}
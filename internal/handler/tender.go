@@ -3,8 +3,9 @@ package handler
 import (
 	"errors"
 	"net/http"
-	"tender-bridge/config"
+	"strings"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
 	"tender-bridge/pkg/validator"
 
 	"github.com/gin-gonic/gin"
@@ -40,11 +41,6 @@ func (h *Handler) createTender(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleClient {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
-		return
-	}
-
 	if err := c.ShouldBindJSON(&body); err != nil {
 		errorResponse(c, http.StatusBadRequest, err)
 		return
@@ -56,7 +52,7 @@ func (h *Handler) createTender(c *gin.Context) {
 		return
 	}
 
-	tenderId, err := h.service.Tender.CreateTender(body)
+	tenderId, err := h.service.Tender.CreateTender(userInfo.Subject(), body)
 	if err != nil {
 		fromError(c, err)
 		return
@@ -76,7 +72,22 @@ func (h *Handler) createTender(c *gin.Context) {
 // @Param limit query int64 true "limit" default(10)
 // @Param page  query int64 true "page" default(1)
 // @Param search  query string false "search"
+// @Param category query string false "category id, subcategories included"
+// @Param tags query string false "comma separated tags the tender must all carry"
+// @Param status query string false "tender status"
+// @Param client query string false "client id"
+// @Param min_budget query int64 false "minimum budget"
+// @Param max_budget query int64 false "maximum budget"
+// @Param deadline_after query string false "deadline from, RFC 3339 or YYYY-MM-DD"
+// @Param deadline_before query string false "deadline until, RFC 3339 or YYYY-MM-DD"
+// @Param created_after query string false "created from, RFC 3339 or YYYY-MM-DD"
+// @Param created_before query string false "created until, RFC 3339 or YYYY-MM-DD"
+// @Param sort query string false "sort field, newest first by default" Enums(budget, deadline, created_at)
+// @Param order query string false "sort order" Enums(asc, desc) default(asc)
+// @Param cursor query string false "X-Next-Cursor or X-Prev-Cursor of an earlier page, replaces page"
 // @Success 200 {object} []models.Tender
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of the previous page"
 // @Failure 400,401,404,500 {object} ErrorResponse
 // @Router /api/client/tenders [get]
 // @Security ApiKeyAuth
@@ -91,17 +102,75 @@ func (h *Handler) getTenders(c *gin.Context) {
 	filter.Limit = pagination.Limit
 	filter.Offset = pagination.Offset
 
+	if filter.Cursor, err = getCursorQuery(c); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
 	search := c.Query(searchQuery)
 	if search != "" {
 		filter.Search = search
 	}
 
-	tenders, _, err := h.service.Tender.GetTenders(filter)
+	if category := c.Query("category"); category != "" {
+		if filter.CategoryId, err = uuid.Parse(category); err != nil {
+			errorResponse(c, http.StatusBadRequest, errors.New("invalid category parameter"))
+			return
+		}
+	}
+
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+
+	if client := c.Query("client"); client != "" {
+		if filter.ClientId, err = uuid.Parse(client); err != nil {
+			errorResponse(c, http.StatusBadRequest, errors.New("invalid client parameter"))
+			return
+		}
+	}
+
+	if filter.MinBudget, err = getInt64Query(c, "min_budget"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter.MaxBudget, err = getInt64Query(c, "max_budget"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter.DeadlineAfter, err = getTimeQuery(c, "deadline_after"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter.DeadlineBefore, err = getTimeQuery(c, "deadline_before"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter.CreatedAfter, err = getTimeQuery(c, "created_after"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter.CreatedBefore, err = getTimeQuery(c, "created_before"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	filter.Status = c.Query("status")
+	filter.Sort = c.Query("sort")
+	filter.Order = c.Query("order")
+
+	tenders, cursors, err := h.service.Tender.GetTenders(filter)
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
+	setPageCursors(c, cursors)
 	c.JSON(http.StatusOK, tenders)
 }
 
@@ -122,7 +191,13 @@ func (h *Handler) getTender(c *gin.Context) {
 		return
 	}
 
-	tender, err := h.service.Tender.GetTender(id)
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tender, err := h.service.Tender.GetTender(userInfo.Subject(), id)
 	if err != nil {
 		fromError(c, err)
 		return
@@ -131,7 +206,7 @@ func (h *Handler) getTender(c *gin.Context) {
 	c.JSON(http.StatusOK, tender)
 }
 
-// @Description Update Tender Status
+// @Description Move the tender along its lifecycle: draft -> published -> closed -> under_evaluation, or cancelled from any of them. Tenders are awarded through the award endpoint.
 // @Summary Update Tender Status
 // @Tags Tender
 // @Accept json
@@ -139,7 +214,7 @@ func (h *Handler) getTender(c *gin.Context) {
 // @Param id path string true "tender id"
 // @Param update body models.UpdateTenderStatus true "update tender status"
 // @Success 200 {object} BaseResponse
-// @Failure 400,401,404,500 {object} ErrorResponse
+// @Failure 400,401,404,409,500 {object} ErrorResponse
 // @Router /api/client/tenders/{id} [put]
 // @Security ApiKeyAuth
 func (h *Handler) updateTenderStatus(c *gin.Context) {
@@ -155,19 +230,19 @@ func (h *Handler) updateTenderStatus(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleClient {
-		errorResponse(c, http.StatusForbidden, errors.New("permission denied"))
+	var body models.UpdateTenderStatus
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	var body models.UpdateTenderStatus
-	if err = c.ShouldBindJSON(&body); err != nil {
+	if err = validator.ValidatePayloads(body); err != nil {
 		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 	body.Id = id
 
-	if err = h.service.Tender.UpdateTenderStatus(body); err != nil {
+	if err = h.service.Tender.UpdateTenderStatus(userInfo.Subject(), body); err != nil {
 		fromError(c, err)
 		return
 	}
@@ -177,6 +252,110 @@ func (h *Handler) updateTenderStatus(c *gin.Context) {
 	})
 }
 
+// @Description Amend the terms of a draft or published tender. Only the fields sent are changed. Changes to anything but the title are material and must be acknowledged by bidders.
+// @Summary Amend Tender
+// @Tags Tender
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Param amend body models.AmendTender true "amend tender"
+// @Success 201 {object} models.TenderRevision
+// @Failure 400,401,404,409,500 {object} ErrorResponse
+// @Router /api/client/tenders/{id}/amendments [post]
+// @Security ApiKeyAuth
+func (h *Handler) amendTender(c *gin.Context) {
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body models.AmendTender
+	if err = c.ShouldBindJSON(&body); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	body.Id = id
+
+	revision, err := h.service.Tender.AmendTender(userInfo.Subject(), body)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, revision)
+}
+
+// @Description Get the revisions of the tender with the changes each amendment made, oldest first
+// @Summary Get Tender Revisions
+// @Tags Tender
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Success 200 {object} []models.TenderRevision
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/client/tenders/{id}/revisions [get]
+// @Security ApiKeyAuth
+func (h *Handler) getTenderRevisions(c *gin.Context) {
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	revisions, err := h.service.Tender.GetTenderRevisions(userInfo.Subject(), id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// @Description Get the status changes of the tender, oldest first
+// @Summary Get Tender History
+// @Tags Tender
+// @Accept json
+// @Produce json
+// @Param id path string true "tender id"
+// @Success 200 {object} []models.TenderStatusChange
+// @Failure 400,401,404,500 {object} ErrorResponse
+// @Router /api/client/tenders/{id}/history [get]
+// @Security ApiKeyAuth
+func (h *Handler) getTenderHistory(c *gin.Context) {
+	id, err := getUUIDParam(c, idQuery)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, errors.New("error: Tender not found"))
+		return
+	}
+
+	userInfo, err := getUserInfo(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	history, err := h.service.Tender.GetTenderHistory(userInfo.Subject(), id)
+	if err != nil {
+		fromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // @Description Delete Tender
 // @Summary Delete Tender
 // @Tags Tender
@@ -200,12 +379,7 @@ func (h *Handler) deleteTender(c *gin.Context) {
 		return
 	}
 
-	if userInfo.Role != config.RoleClient {
-		errorResponse(c, http.StatusForbidden, errors.New("error: Tender not found or access denied"))
-		return
-	}
-
-	if err = h.service.Tender.DeleteTender(id); err != nil {
+	if err = h.service.Tender.DeleteTender(userInfo.Subject(), id); err != nil {
 		fromError(c, err)
 		return
 	}
@@ -223,7 +397,10 @@ func (h *Handler) deleteTender(c *gin.Context) {
 // @Param id path string true "user id"
 // @Param page query string true "page" Default(1)
 // @Param limit query string true "limit" Default(10)
+// @Param cursor query string false "X-Next-Cursor or X-Prev-Cursor of an earlier page, replaces page"
 // @Success 200 {object} []models.Tender
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of the previous page"
 // @Failure 400,401,404,500 {object} ErrorResponse
 // @Router /api/users/{id}/tenders [get]
 // @Security ApiKeyAuth
@@ -245,7 +422,7 @@ func (h *Handler) getUserTenders(c *gin.Context) {
 		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	if userInfo.Id != userId {
+	if !policy.CanAccessUser(userInfo.Subject(), policy.UserActivityRead, userId) {
 		errorResponse(c, http.StatusForbidden, errors.New("access denied"))
 		return
 	}
@@ -254,12 +431,19 @@ func (h *Handler) getUserTenders(c *gin.Context) {
 	tenderFilter.Limit = pagination.Limit
 	tenderFilter.Offset = pagination.Offset
 	tenderFilter.ClientId = userId
+	tenderFilter.IncludeDrafts = true
+
+	if tenderFilter.Cursor, err = getCursorQuery(c); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
 
-	bids, _, err := h.service.Tender.GetTenders(tenderFilter)
+	bids, cursors, err := h.service.Tender.GetTenders(tenderFilter)
 	if err != nil {
 		fromError(c, err)
 		return
 	}
 
+	setPageCursors(c, cursors)
 	c.JSON(http.StatusOK, bids)
 }
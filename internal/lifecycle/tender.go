@@ -0,0 +1,42 @@
+package lifecycle
+
+import (
+	"slices"
+	"tender-bridge/config"
+)
+
+// tenderTransitions lists the statuses a tender may move to from each
+// status. Awarded and cancelled tenders are final.
+var tenderTransitions = map[string][]string{
+	config.TenderStatusDraft: {
+		config.TenderStatusPublished,
+		config.TenderStatusCancelled,
+	},
+	config.TenderStatusPublished: {
+		config.TenderStatusClosed,
+		config.TenderStatusCancelled,
+	},
+	config.TenderStatusClosed: {
+		config.TenderStatusUnderEvaluation,
+		config.TenderStatusCancelled,
+	},
+	config.TenderStatusUnderEvaluation: {
+		config.TenderStatusAwarded,
+		config.TenderStatusCancelled,
+	},
+	config.TenderStatusAwarded:   {},
+	config.TenderStatusCancelled: {},
+}
+
+// IsTenderStatus reports whether status is a known tender status
+func IsTenderStatus(status string) bool {
+	_, ok := tenderTransitions[status]
+	return ok
+}
+
+// CanTransitionTender reports whether a tender in status from may be moved
+// to status to. Conditions that depend on the tender itself, such as its
+// deadline or bids, are checked by the caller.
+func CanTransitionTender(from, to string) bool {
+	return slices.Contains(tenderTransitions[from], to)
+}
@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tender-bridge/config"
+	"tender-bridge/pkg/logger"
+	"time"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers outgoing email. Implementations must be safe for
+// concurrent use.
+type Sender interface {
+	Send(message Message) error
+}
+
+// NewSender returns the sender selected by MAIL_DRIVER
+func NewSender(cfg *config.Config, logger *logger.Logger) (Sender, error) {
+	switch cfg.MailDriver {
+	case DriverLog, "":
+		return NewLogSender(cfg.MailFrom, logger), nil
+	case DriverFile:
+		return NewFileSender(cfg.MailFrom, cfg.MailDir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+}
+
+type logSender struct {
+	from   string
+	logger *logger.Logger
+}
+
+// NewLogSender writes every message to the application log, intended for
+// local development only.
+func NewLogSender(from string, logger *logger.Logger) *logSender {
+	return &logSender{
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (s *logSender) Send(message Message) error {
+	s.logger.WithField("from", s.from).
+		WithField("to", message.To).
+		WithField("subject", message.Subject).
+		Info(message.Body)
+
+	return nil
+}
+
+type fileSender struct {
+	from string
+	dir  string
+}
+
+// NewFileSender stores every message as a separate .eml file in dir
+func NewFileSender(from, dir string) (*fileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &fileSender{
+		from: from,
+		dir:  dir,
+	}, nil
+}
+
+func (s *fileSender) Send(message Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(message.To))
+
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		s.from,
+		message.To,
+		message.Subject,
+		now.Format(time.RFC1123Z),
+		message.Body,
+	)
+
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	Id         uuid.UUID  `json:"id"`
+	UserId     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKey struct {
+	UserId    uuid.UUID  `json:"-"`
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Prefix    string     `json:"-"`
+	KeyHash   string     `json:"-"`
+}
+
+// CreatedAPIKey carries the plain key, which is only ever returned once
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// CreateAuditLog records a security relevant event. ActorId is the user who
+// caused it, UserId the account it concerns; either may be unknown.
+type CreateAuditLog struct {
+	Action    string
+	ActorId   *uuid.UUID
+	UserId    *uuid.UUID
+	IPAddress string
+	Details   string
+}
@@ -19,3 +19,7 @@ type Register struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
+
+type Logout struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -1,27 +1,48 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Bid struct {
-	Id           uuid.UUID `json:"id"`
-	ContractorId uuid.UUID `json:"contractor_id"`
-	TenderId     uuid.UUID `json:"-"`
-	Tender       Tender    `json:"tender"`
-	Price        int64     `json:"price"`
-	DeliveryTime int       `json:"delivery_time"`
-	Comment      string    `json:"comments"`
-	Status       string    `json:"status"`
+	Id             uuid.UUID `json:"id"`
+	OrganizationId uuid.UUID `json:"organization_id"`
+	ContractorId   uuid.UUID `json:"contractor_id"`
+	TenderId       uuid.UUID `json:"-"`
+	Tender         Tender    `json:"tender"`
+	Price          int64     `json:"price"`
+	DeliveryTime   int       `json:"delivery_time"`
+	Comment        string    `json:"comments"`
+	Status         string    `json:"status"`
+
+	// Sealed bids have no price, delivery time or comment until the
+	// tender's bids are opened
+	Sealed     bool   `json:"sealed"`
+	SealedData string `json:"-"`
+
+	// AcknowledgedRevision is the latest material amendment of the tender
+	// the bidder has acknowledged
+	AcknowledgedRevision int `json:"acknowledged_revision"`
+
+	// Rank and Snippet are only set in search results. Snippet is an HTML
+	// escaped excerpt of the comment with the matches wrapped in <mark> tags.
+	Rank    float32 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type CreateBid struct {
-	ContractorId uuid.UUID `json:"-"`
-	TenderId     uuid.UUID `json:"-"`
-	Price        int64     `json:"price"`
-	DeliveryTime int       `json:"delivery_time"`
-	Comment      string    `json:"comments"`
-	Status       string    `json:"-"`
+	OrganizationId uuid.UUID `json:"organization_id"`
+	ContractorId   uuid.UUID `json:"-"`
+	TenderId       uuid.UUID `json:"-"`
+	Price          int64     `json:"price"`
+	DeliveryTime   int       `json:"delivery_time"`
+	Comment        string    `json:"comments"`
+	Status         string    `json:"-"`
+	SealedData     string    `json:"-"`
 }
 
 type UpdateBid struct {
@@ -35,13 +56,17 @@ type UpdateBid struct {
 }
 
 type BidFilter struct {
-	Search       string
-	FromPrice    int64
-	ToPrice      int64
-	TenderId     uuid.UUID
-	ContractorId uuid.UUID
-	Limit        int
-	Offset       int
+	Search          string
+	FromPrice       int64
+	ToPrice         int64
+	TenderId        uuid.UUID
+	ContractorId    uuid.UUID
+	OrganizationIds []uuid.UUID
+	Limit           int
+	Offset          int
+
+	// Cursor pages from a row instead of an offset
+	Cursor *Cursor
 }
 
 type BidNotification struct {
@@ -49,3 +74,22 @@ type BidNotification struct {
 	BidID   string `json:"bid_id"`
 	Message string `json:"message"`
 }
+
+// SealedBidData is the part of a sealed bid that is encrypted
+type SealedBidData struct {
+	Price        int64  `json:"price"`
+	DeliveryTime int    `json:"delivery_time"`
+	Comment      string `json:"comment"`
+}
+
+type UnsealedBid struct {
+	Id uuid.UUID
+	SealedBidData
+}
+
+// SealedBids is returned instead of the bids of a sealed tender until they
+// are opened
+type SealedBids struct {
+	Sealed bool `json:"sealed"`
+	Count  int  `json:"count"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a trade tenders are filed under. Categories form a tree, such
+// as construction > electrical.
+type Category struct {
+	Id        uuid.UUID  `json:"id"`
+	ParentId  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	CreatedAt time.Time  `json:"created_at"`
+	Children  []Category `json:"children,omitempty"`
+}
+
+type CreateCategory struct {
+	ParentId *uuid.UUID `json:"parent_id"`
+	Name     string     `json:"name" validate:"required"`
+	Slug     string     `json:"slug"`
+}
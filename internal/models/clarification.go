@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clarification is a question a contractor asked about a tender and the
+// client's answer. Who asked is only shown to the tender's owners.
+type Clarification struct {
+	Id             uuid.UUID  `json:"id"`
+	TenderId       uuid.UUID  `json:"tender_id"`
+	OrganizationId *uuid.UUID `json:"organization_id,omitempty"`
+	AskedBy        *uuid.UUID `json:"asked_by,omitempty"`
+	Question       string     `json:"question"`
+	Answer         *string    `json:"answer"`
+	AnsweredAt     *time.Time `json:"answered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type CreateClarification struct {
+	OrganizationId uuid.UUID `json:"organization_id"`
+	TenderId       uuid.UUID `json:"-"`
+	AskedBy        uuid.UUID `json:"-"`
+	Question       string    `json:"question" validate:"required"`
+}
+
+type AnswerClarification struct {
+	Id         uuid.UUID `json:"-"`
+	TenderId   uuid.UUID `json:"-"`
+	AnsweredBy uuid.UUID `json:"-"`
+	Answer     string    `json:"answer" validate:"required"`
+}
+
+type ClarificationFilter struct {
+	TenderId uuid.UUID
+
+	// OrganizationIds limits unanswered questions to the ones asked by
+	// these organizations. Answered questions are always listed.
+	OrganizationIds []uuid.UUID
+	// All lists every question, which only the tender's owners may do
+	All bool
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerificationToken struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type CreateEmailVerificationToken struct {
+	UserId    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/google/uuid"
+
 type Pagination struct {
 	Page       int `json:"page"  default:"1"`
 	Limit      int `json:"limit" default:"10"`
@@ -7,3 +9,21 @@ type Pagination struct {
 	PageCount  int `json:"page_count"`
 	TotalCount int `json:"total_count"`
 }
+
+// Cursor marks a row of a list sorted by Sort and Order. Value is the sort
+// key of the row and Id breaks ties. Backward cursors page towards the start
+// of the list.
+type Cursor struct {
+	Sort     string    `json:"s,omitempty"`
+	Order    string    `json:"o,omitempty"`
+	Value    string    `json:"v"`
+	Id       uuid.UUID `json:"i"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// PageCursors point to the pages next to a list page. They are empty at the
+// ends of the list.
+type PageCursors struct {
+	Next string
+	Prev string
+}
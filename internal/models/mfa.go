@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserMFA struct {
+	UserId     uuid.UUID
+	TotpSecret string
+	EnabledAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type MFACode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type LoginMFA struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Organization struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateOrganization struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type OrganizationMember struct {
+	OrganizationId uuid.UUID `json:"-"`
+	UserId         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type UpdateMemberRole struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type OrganizationInvitation struct {
+	Id             uuid.UUID
+	OrganizationId uuid.UUID
+	Email          string
+	Role           string
+	TokenHash      string
+	InvitedBy      *uuid.UUID
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+type CreateOrganizationInvitation struct {
+	OrganizationId uuid.UUID
+	Email          string
+	Role           string
+	TokenHash      string
+	InvitedBy      uuid.UUID
+	ExpiresAt      time.Time
+}
+
+type InviteMember struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required"`
+}
+
+type AcceptInvitation struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login on one device. Its id is the family id of the refresh
+// tokens rotated from that login.
+type Session struct {
+	Id         uuid.UUID  `json:"id"`
+	UserId     uuid.UUID  `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current"`
+}
+
+type CreateSession struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	UserAgent string
+	IPAddress string
+	ExpiresAt time.Time
+}
+
+// Client describes the device a request was made from
+type Client struct {
+	IP        string
+	UserAgent string
+}
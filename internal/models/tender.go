@@ -15,34 +15,119 @@ type Tender struct {
 	File        string    `json:"file"`
 	Status      string    `json:"status"`
 
-	ClientId uuid.UUID `json:"-"`
-	Client   User      `json:"client"`
+	// Sealed tenders hide bid contents until bidding closes and the bids
+	// are opened
+	Sealed       bool       `json:"sealed"`
+	BidsOpenedAt *time.Time `json:"bids_opened_at"`
+
+	// Revision counts the amendments made to the tender. Bidders must
+	// acknowledge MaterialRevision before their bid can win.
+	Revision         int `json:"revision"`
+	MaterialRevision int `json:"material_revision"`
+
+	CategoryId *uuid.UUID `json:"category_id"`
+	Tags       []string   `json:"tags"`
+
+	// Rank and Snippet are only set in search results. Snippet is an HTML
+	// escaped excerpt of the description with the matches wrapped in <mark>
+	// tags.
+	Rank    float32 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+
+	OrganizationId uuid.UUID `json:"organization_id"`
+	ClientId       uuid.UUID `json:"-"`
+	Client         User      `json:"client"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type CreateTender struct {
-	ClientId    uuid.UUID `json:"-"`
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description" validate:"required"`
-	Deadline    string    `json:"deadline" validate:"required"`
-	Budget      int64     `json:"budget"`
-	File        string    `json:"file"`
-	Status      string    `json:"-"`
+	OrganizationId uuid.UUID `json:"organization_id"`
+	ClientId       uuid.UUID `json:"-"`
+	Title          string    `json:"title" validate:"required"`
+	Description    string    `json:"description" validate:"required"`
+	Deadline       string    `json:"deadline" validate:"required"`
+	Budget         int64     `json:"budget"`
+	File           string    `json:"file"`
+	Status         string    `json:"-"`
+
+	// Draft keeps the tender hidden from contractors until it is published
+	Draft bool `json:"draft"`
+	// Sealed keeps bid contents encrypted until the tender closes
+	Sealed bool `json:"sealed"`
+
+	CategoryId *uuid.UUID `json:"category_id"`
+	Tags       []string   `json:"tags"`
 }
 
-type UpdateTender struct {
+// AmendTender changes the terms of a tender. Fields left out keep their
+// current value.
+type AmendTender struct {
 	Id          uuid.UUID `json:"-"`
-	ClientId    uuid.UUID `json:"-"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Deadline    time.Time `json:"deadline"`
-	Budget      int64     `json:"budget"`
-	File        string    `json:"file"`
-	Status      string    `json:"status"`
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Deadline    *string   `json:"deadline"`
+	Budget      *int64    `json:"budget"`
+	File        *string   `json:"file"`
+	Reason      string    `json:"reason"`
+}
+
+// FieldChange is the value of an amended field before and after
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// CreateTenderRevision stores the terms of the tender after an amendment
+// made on top of revision Base
+type CreateTenderRevision struct {
+	TenderId    uuid.UUID
+	Base        int
+	Title       string
+	Description string
+	Deadline    time.Time
+	Budget      int64
+	File        string
+	Material    bool
+	Changes     map[string]FieldChange
+	Reason      string
+	ChangedBy   *uuid.UUID
+}
+
+type TenderRevision struct {
+	Id        uuid.UUID              `json:"id"`
+	TenderId  uuid.UUID              `json:"tender_id"`
+	Number    int                    `json:"number"`
+	Material  bool                   `json:"material"`
+	Changes   map[string]FieldChange `json:"changes"`
+	Reason    string                 `json:"reason"`
+	ChangedBy *uuid.UUID             `json:"changed_by"`
+	CreatedAt time.Time              `json:"created_at"`
 }
 
 type UpdateTenderStatus struct {
 	Id     uuid.UUID `json:"-"`
-	Status string    `json:"status"`
+	Status string    `json:"status" validate:"required"`
+	Reason string    `json:"reason"`
+}
+
+// TenderTransition moves a tender from one status to another. ChangedBy is
+// nil for changes made by the system.
+type TenderTransition struct {
+	TenderId  uuid.UUID
+	From      string
+	To        string
+	ChangedBy *uuid.UUID
+	Reason    string
+}
+
+type TenderStatusChange struct {
+	Id         uuid.UUID  `json:"id"`
+	TenderId   uuid.UUID  `json:"tender_id"`
+	FromStatus *string    `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	ChangedBy  *uuid.UUID `json:"changed_by"`
+	Reason     string     `json:"reason"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 type TenderFilter struct {
@@ -50,4 +135,31 @@ type TenderFilter struct {
 	Limit    int
 	Offset   int
 	ClientId uuid.UUID
+
+	// IncludeDrafts lists draft tenders as well, which only their owners
+	// may see
+	IncludeDrafts bool
+
+	// CategoryId matches the category and its subcategories, Tags the
+	// tenders carrying all of the tags
+	CategoryId uuid.UUID
+	Tags       []string
+
+	Status string
+
+	// Ranges are inclusive, nil leaves that end open
+	MinBudget      *int64
+	MaxBudget      *int64
+	DeadlineAfter  *time.Time
+	DeadlineBefore *time.Time
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+
+	// Sort is one of the TenderSort fields and Order asc or desc. Without
+	// it search results come by relevance and other lists newest first.
+	Sort  string
+	Order string
+
+	// Cursor pages from a row instead of an offset
+	Cursor *Cursor
 }
@@ -1,13 +1,19 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
-	Id       uuid.UUID `json:"id"`
-	Role     string    `json:"role"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Password string    `json:"-"`
+	Id              uuid.UUID  `json:"id"`
+	Role            string     `json:"role"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	SuspendedAt     *time.Time `json:"suspended_at"`
 }
 
 type CreateUser struct {
@@ -19,10 +25,27 @@ type CreateUser struct {
 
 type UserFilter struct {
 	Search string
+	Role   string
 	Limit  int
 	Offset int
 }
 
+type UpdateUserRole struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// UpdateProfile changes the current user's own account. Omitted fields are
+// left as they are.
+type UpdateProfile struct {
+	Username *string `json:"username" validate:"omitempty,min=1,max=64"`
+	Email    *string `json:"email" validate:"omitempty,email,max=64"`
+}
+
+type ChangePassword struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
 type UpdateUser struct {
 	Id       uuid.UUID `json:"-"`
 	Role     string    `json:"role" validate:"required"`
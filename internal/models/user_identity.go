@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+type CreateUserIdentity struct {
+	UserId   uuid.UUID
+	Provider string
+	Subject  string
+	Email    string
+}
+
+// OIDCState is kept between redirecting to the provider and its callback
+type OIDCState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
@@ -0,0 +1,235 @@
+package policy
+
+import (
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Action names a single operation that can be authorized
+type Action string
+
+const (
+	TenderCreate   Action = "tender:create"
+	TenderList     Action = "tender:list"
+	TenderRead     Action = "tender:read"
+	TenderUpdate   Action = "tender:update"
+	TenderDelete   Action = "tender:delete"
+	TenderListBids Action = "tender:list_bids"
+	TenderAward    Action = "tender:award"
+	TenderHistory  Action = "tender:history"
+
+	BidSubmit  Action = "bid:submit"
+	BidListOwn Action = "bid:list_own"
+	BidDelete  Action = "bid:delete"
+
+	// BidAcknowledge confirms that the bidder has seen a tender amendment
+	BidAcknowledge Action = "bid:acknowledge"
+
+	ClarificationAsk    Action = "clarification:ask"
+	ClarificationAnswer Action = "clarification:answer"
+
+	CategoryManage    Action = "category:manage"
+	CategorySubscribe Action = "category:subscribe"
+
+	UserActivityRead Action = "user:activity_read"
+	UserManage       Action = "user:manage"
+
+	OrganizationRead          Action = "organization:read"
+	OrganizationInvite        Action = "organization:invite"
+	OrganizationManageMembers Action = "organization:manage_members"
+)
+
+// Subject is the authenticated caller an action is checked for
+type Subject struct {
+	Id   uuid.UUID
+	Role string
+
+	// Memberships maps organization ids to the subject's role in them. Only
+	// the organizations relevant to the checked resource need to be present.
+	Memberships map[uuid.UUID]string
+}
+
+// permissions lists what each role may do at all. Whether the caller may do
+// it to a particular tender or bid is decided by the ownership rules below.
+var permissions = map[string]map[Action]bool{
+	config.RoleAdmin: {
+		TenderList:       true,
+		TenderRead:       true,
+		TenderUpdate:     true,
+		TenderDelete:     true,
+		TenderListBids:   true,
+		TenderHistory:    true,
+		UserActivityRead: true,
+		UserManage:       true,
+		CategoryManage:   true,
+	},
+	config.RoleClient: {
+		TenderCreate:     true,
+		TenderList:       true,
+		TenderRead:       true,
+		TenderUpdate:     true,
+		TenderDelete:     true,
+		TenderListBids:   true,
+		TenderAward:      true,
+		TenderHistory:    true,
+		UserActivityRead: true,
+
+		ClarificationAnswer: true,
+
+		OrganizationRead:          true,
+		OrganizationInvite:        true,
+		OrganizationManageMembers: true,
+	},
+	config.RoleContractor: {
+		TenderList:       true,
+		TenderRead:       true,
+		BidSubmit:        true,
+		BidListOwn:       true,
+		BidDelete:        true,
+		BidAcknowledge:   true,
+		UserActivityRead: true,
+
+		ClarificationAsk:  true,
+		CategorySubscribe: true,
+
+		OrganizationRead:          true,
+		OrganizationInvite:        true,
+		OrganizationManageMembers: true,
+	},
+}
+
+// organizationPermissions lists what each organization role may do to the
+// tenders, bids and membership of that organization
+var organizationPermissions = map[string]map[Action]bool{
+	config.OrgRoleOwner: {
+		TenderCreate:   true,
+		TenderRead:     true,
+		TenderUpdate:   true,
+		TenderDelete:   true,
+		TenderListBids: true,
+		TenderAward:    true,
+		TenderHistory:  true,
+		BidSubmit:      true,
+		BidDelete:      true,
+		BidAcknowledge: true,
+
+		ClarificationAsk:    true,
+		ClarificationAnswer: true,
+
+		OrganizationRead:          true,
+		OrganizationInvite:        true,
+		OrganizationManageMembers: true,
+	},
+	config.OrgRoleManager: {
+		TenderCreate:   true,
+		TenderRead:     true,
+		TenderUpdate:   true,
+		TenderDelete:   true,
+		TenderListBids: true,
+		TenderAward:    true,
+		TenderHistory:  true,
+		BidSubmit:      true,
+		BidDelete:      true,
+		BidAcknowledge: true,
+
+		ClarificationAsk:    true,
+		ClarificationAnswer: true,
+
+		OrganizationRead:   true,
+		OrganizationInvite: true,
+	},
+	config.OrgRoleViewer: {
+		TenderRead:     true,
+		TenderListBids: true,
+		TenderHistory:  true,
+
+		OrganizationRead: true,
+	},
+}
+
+// Can reports whether the role is allowed to perform the action on any
+// resource
+func Can(role string, action Action) bool {
+	return permissions[role][action]
+}
+
+// CanAccessTender applies the role permission and the ownership rule of the
+// action to a specific tender
+func CanAccessTender(subject Subject, action Action, tender models.Tender) bool {
+	if !Can(subject.Role, action) {
+		return false
+	}
+
+	switch action {
+	case TenderRead:
+		// contractors read any published tender to bid on it, clients only
+		// the ones of their organizations. Drafts stay with their owners.
+		if subject.Role == config.RoleAdmin || memberCan(subject, tender.OrganizationId, action) {
+			return true
+		}
+		return subject.Role == config.RoleContractor && tender.Status != config.TenderStatusDraft
+	case TenderUpdate, TenderDelete, TenderListBids, TenderHistory:
+		return subject.Role == config.RoleAdmin || memberCan(subject, tender.OrganizationId, action)
+	case TenderAward, ClarificationAnswer:
+		return memberCan(subject, tender.OrganizationId, action)
+	default:
+		return true
+	}
+}
+
+// CanAccessBid applies the role permission and the ownership rule of the
+// action to a specific bid
+func CanAccessBid(subject Subject, action Action, bid models.Bid) bool {
+	if !Can(subject.Role, action) {
+		return false
+	}
+
+	switch action {
+	case BidDelete, BidAcknowledge:
+		return memberCan(subject, bid.OrganizationId, action)
+	default:
+		return true
+	}
+}
+
+// CanAccessUser applies the role permission to data belonging to the user
+// with the given id. Users may only see their own activity.
+func CanAccessUser(subject Subject, action Action, userId uuid.UUID) bool {
+	if !Can(subject.Role, action) {
+		return false
+	}
+
+	return subject.Role == config.RoleAdmin || subject.Id == userId
+}
+
+// CanAccessOrganization applies the role permission and the subject's
+// organization role to an action taken in the organization, such as creating
+// a tender or inviting a member
+func CanAccessOrganization(subject Subject, action Action, organizationId uuid.UUID) bool {
+	if !Can(subject.Role, action) {
+		return false
+	}
+
+	return memberCan(subject, organizationId, action)
+}
+
+// CanAssignOrganizationRole reports whether a member with the given role may
+// hand out the target role. Only owners can create other owners.
+func CanAssignOrganizationRole(role, target string) bool {
+	if _, ok := organizationPermissions[target]; !ok {
+		return false
+	}
+
+	return role == config.OrgRoleOwner || target != config.OrgRoleOwner
+}
+
+func memberCan(subject Subject, organizationId uuid.UUID, action Action) bool {
+	role, ok := subject.Memberships[organizationId]
+	if !ok {
+		return false
+	}
+
+	return organizationPermissions[role][action]
+}
@@ -0,0 +1,196 @@
+package policy
+
+import (
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type resource int
+
+const (
+	anyResource resource = iota
+	tenderResource
+	bidResource
+	userResource
+	organizationResource
+)
+
+var (
+	ownOrganization   = uuid.New()
+	otherOrganization = uuid.New()
+	self              = uuid.New()
+	otherUser         = uuid.New()
+)
+
+// accessCase checks one action of a caller with a system role and, when
+// orgRole is set, that role in ownOrganization. The resource belongs to
+// ownOrganization (or is the caller) when own is true.
+type accessCase struct {
+	name     string
+	role     string
+	orgRole  string
+	resource resource
+	action   Action
+	own      bool
+	status   string
+	want     bool
+}
+
+var accessCases = []accessCase{
+	// role permissions without a resource
+	{name: "admin manages users", role: config.RoleAdmin, action: UserManage, want: true},
+	{name: "admin manages categories", role: config.RoleAdmin, action: CategoryManage, want: true},
+	{name: "admin cannot create tenders", role: config.RoleAdmin, action: TenderCreate, want: false},
+	{name: "admin cannot bid", role: config.RoleAdmin, action: BidSubmit, want: false},
+	{name: "client creates tenders", role: config.RoleClient, action: TenderCreate, want: true},
+	{name: "client cannot bid", role: config.RoleClient, action: BidSubmit, want: false},
+	{name: "client cannot manage users", role: config.RoleClient, action: UserManage, want: false},
+	{name: "contractor bids", role: config.RoleContractor, action: BidSubmit, want: true},
+	{name: "contractor subscribes to categories", role: config.RoleContractor, action: CategorySubscribe, want: true},
+	{name: "contractor cannot create tenders", role: config.RoleContractor, action: TenderCreate, want: false},
+	{name: "contractor cannot award", role: config.RoleContractor, action: TenderAward, want: false},
+	{name: "unknown role can do nothing", role: "guest", action: TenderList, want: false},
+
+	// getTender, which used to return drafts to anyone
+	{name: "admin reads other draft", role: config.RoleAdmin, resource: tenderResource, action: TenderRead, status: config.TenderStatusDraft, want: true},
+	{name: "owner reads own draft", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderRead, own: true, status: config.TenderStatusDraft, want: true},
+	{name: "viewer reads own draft", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: tenderResource, action: TenderRead, own: true, status: config.TenderStatusDraft, want: true},
+	{name: "getTender: client cannot read other draft", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderRead, status: config.TenderStatusDraft, want: false},
+	{name: "client cannot read other published", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderRead, status: config.TenderStatusPublished, want: false},
+	{name: "contractor reads published", role: config.RoleContractor, resource: tenderResource, action: TenderRead, status: config.TenderStatusPublished, want: true},
+	{name: "contractor reads closed", role: config.RoleContractor, resource: tenderResource, action: TenderRead, status: config.TenderStatusClosed, want: true},
+	{name: "getTender: contractor cannot read draft", role: config.RoleContractor, resource: tenderResource, action: TenderRead, status: config.TenderStatusDraft, want: false},
+
+	// updateTenderStatus, which used to skip the ownership check, amendments
+	// and deletion
+	{name: "admin updates other tender", role: config.RoleAdmin, resource: tenderResource, action: TenderUpdate, status: config.TenderStatusPublished, want: true},
+	{name: "owner updates own tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderUpdate, own: true, status: config.TenderStatusPublished, want: true},
+	{name: "manager updates own tender", role: config.RoleClient, orgRole: config.OrgRoleManager, resource: tenderResource, action: TenderUpdate, own: true, status: config.TenderStatusDraft, want: true},
+	{name: "updateTenderStatus: viewer cannot update own tender", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: tenderResource, action: TenderUpdate, own: true, status: config.TenderStatusPublished, want: false},
+	{name: "updateTenderStatus: owner cannot update other tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderUpdate, status: config.TenderStatusPublished, want: false},
+	{name: "updateTenderStatus: owner cannot update other draft", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderUpdate, status: config.TenderStatusDraft, want: false},
+	{name: "contractor owner cannot update tender", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderUpdate, own: true, status: config.TenderStatusPublished, want: false},
+	{name: "owner deletes own tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderDelete, own: true, want: true},
+	{name: "viewer cannot delete own tender", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: tenderResource, action: TenderDelete, own: true, want: false},
+	{name: "owner cannot delete other tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderDelete, want: false},
+
+	// bids, history, awards and clarifications of a tender
+	{name: "viewer lists bids of own tender", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: tenderResource, action: TenderListBids, own: true, want: true},
+	{name: "owner cannot list bids of other tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderListBids, want: false},
+	{name: "admin reads other history", role: config.RoleAdmin, resource: tenderResource, action: TenderHistory, want: true},
+	{name: "client cannot read other history", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderHistory, want: false},
+	{name: "manager awards own tender", role: config.RoleClient, orgRole: config.OrgRoleManager, resource: tenderResource, action: TenderAward, own: true, want: true},
+	{name: "viewer cannot award own tender", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: tenderResource, action: TenderAward, own: true, want: false},
+	{name: "owner cannot award other tender", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: TenderAward, want: false},
+	{name: "admin cannot award", role: config.RoleAdmin, resource: tenderResource, action: TenderAward, want: false},
+	{name: "owner answers own clarifications", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: ClarificationAnswer, own: true, want: true},
+	{name: "owner cannot answer other clarifications", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: tenderResource, action: ClarificationAnswer, want: false},
+	{name: "contractor asks on any tender", role: config.RoleContractor, resource: tenderResource, action: ClarificationAsk, status: config.TenderStatusPublished, want: true},
+
+	// bids
+	{name: "owner deletes own bid", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: bidResource, action: BidDelete, own: true, want: true},
+	{name: "manager deletes own bid", role: config.RoleContractor, orgRole: config.OrgRoleManager, resource: bidResource, action: BidDelete, own: true, want: true},
+	{name: "viewer cannot delete own bid", role: config.RoleContractor, orgRole: config.OrgRoleViewer, resource: bidResource, action: BidDelete, own: true, want: false},
+	{name: "contractor cannot delete other bid", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: bidResource, action: BidDelete, want: false},
+	{name: "client cannot delete own org bid", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: bidResource, action: BidDelete, own: true, want: false},
+	{name: "manager acknowledges own bid", role: config.RoleContractor, orgRole: config.OrgRoleManager, resource: bidResource, action: BidAcknowledge, own: true, want: true},
+	{name: "contractor cannot acknowledge other bid", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: bidResource, action: BidAcknowledge, want: false},
+
+	// user activity
+	{name: "admin reads other activity", role: config.RoleAdmin, resource: userResource, action: UserActivityRead, want: true},
+	{name: "client reads own activity", role: config.RoleClient, resource: userResource, action: UserActivityRead, own: true, want: true},
+	{name: "client cannot read other activity", role: config.RoleClient, resource: userResource, action: UserActivityRead, want: false},
+	{name: "contractor reads own activity", role: config.RoleContractor, resource: userResource, action: UserActivityRead, own: true, want: true},
+	{name: "contractor cannot read other activity", role: config.RoleContractor, resource: userResource, action: UserActivityRead, want: false},
+	{name: "client cannot manage self", role: config.RoleClient, resource: userResource, action: UserManage, own: true, want: false},
+
+	// organizations
+	{name: "owner creates tenders in own org", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: organizationResource, action: TenderCreate, own: true, want: true},
+	{name: "viewer cannot create tenders in own org", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: organizationResource, action: TenderCreate, own: true, want: false},
+	{name: "owner cannot create tenders in other org", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: organizationResource, action: TenderCreate, want: false},
+	{name: "contractor owner cannot create tenders", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: organizationResource, action: TenderCreate, own: true, want: false},
+	{name: "contractor manager bids for own org", role: config.RoleContractor, orgRole: config.OrgRoleManager, resource: organizationResource, action: BidSubmit, own: true, want: true},
+	{name: "contractor viewer cannot bid for own org", role: config.RoleContractor, orgRole: config.OrgRoleViewer, resource: organizationResource, action: BidSubmit, own: true, want: false},
+	{name: "contractor cannot bid for other org", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: organizationResource, action: BidSubmit, want: false},
+	{name: "manager invites to own org", role: config.RoleClient, orgRole: config.OrgRoleManager, resource: organizationResource, action: OrganizationInvite, own: true, want: true},
+	{name: "viewer cannot invite to own org", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: organizationResource, action: OrganizationInvite, own: true, want: false},
+	{name: "owner manages own members", role: config.RoleContractor, orgRole: config.OrgRoleOwner, resource: organizationResource, action: OrganizationManageMembers, own: true, want: true},
+	{name: "manager cannot manage members", role: config.RoleClient, orgRole: config.OrgRoleManager, resource: organizationResource, action: OrganizationManageMembers, own: true, want: false},
+	{name: "viewer reads own org", role: config.RoleClient, orgRole: config.OrgRoleViewer, resource: organizationResource, action: OrganizationRead, own: true, want: true},
+	{name: "owner cannot read other org", role: config.RoleClient, orgRole: config.OrgRoleOwner, resource: organizationResource, action: OrganizationRead, want: false},
+	{name: "admin cannot read orgs", role: config.RoleAdmin, resource: organizationResource, action: OrganizationRead, want: false},
+}
+
+func TestAccess(t *testing.T) {
+	for _, tc := range accessCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject := Subject{
+				Id:          self,
+				Role:        tc.role,
+				Memberships: map[uuid.UUID]string{},
+			}
+			if tc.orgRole != "" {
+				subject.Memberships[ownOrganization] = tc.orgRole
+			}
+
+			organizationId, userId := otherOrganization, otherUser
+			if tc.own {
+				organizationId, userId = ownOrganization, self
+			}
+
+			var got bool
+			switch tc.resource {
+			case tenderResource:
+				got = CanAccessTender(subject, tc.action, models.Tender{
+					Id:             uuid.New(),
+					OrganizationId: organizationId,
+					Status:         tc.status,
+				})
+			case bidResource:
+				got = CanAccessBid(subject, tc.action, models.Bid{
+					Id:             uuid.New(),
+					OrganizationId: organizationId,
+				})
+			case userResource:
+				got = CanAccessUser(subject, tc.action, userId)
+			case organizationResource:
+				got = CanAccessOrganization(subject, tc.action, organizationId)
+			default:
+				got = Can(tc.role, tc.action)
+			}
+
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanAssignOrganizationRole(t *testing.T) {
+	cases := []struct {
+		role   string
+		target string
+		want   bool
+	}{
+		{config.OrgRoleOwner, config.OrgRoleOwner, true},
+		{config.OrgRoleOwner, config.OrgRoleManager, true},
+		{config.OrgRoleOwner, config.OrgRoleViewer, true},
+		{config.OrgRoleManager, config.OrgRoleOwner, false},
+		{config.OrgRoleManager, config.OrgRoleManager, true},
+		{config.OrgRoleManager, config.OrgRoleViewer, true},
+		{config.OrgRoleViewer, config.OrgRoleOwner, false},
+		{config.OrgRoleOwner, "admin", false},
+		{config.OrgRoleOwner, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.role+"->"+tc.target, func(t *testing.T) {
+			if got := CanAssignOrganizationRole(tc.role, tc.target); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type apiKeyRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewAPIKeyRepo(db *sqlx.DB, logger *logger.Logger) *apiKeyRepo {
+	return &apiKeyRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *apiKeyRepo) Create(request models.CreateAPIKey) (models.APIKey, error) {
+	var key models.APIKey
+
+	query := `
+	INSERT INTO api_keys (
+		id,
+		user_id,
+		name,
+		prefix,
+		key_hash,
+		scopes,
+		expires_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING
+		id,
+		user_id,
+		name,
+		prefix,
+		key_hash,
+		scopes,
+		expires_at,
+		last_used_at,
+		revoked_at,
+		created_at;`
+
+	if err := r.db.QueryRow(query,
+		uuid.New(),
+		request.UserId,
+		request.Name,
+		request.Prefix,
+		request.KeyHash,
+		pq.Array(request.Scopes),
+		request.ExpiresAt,
+	).Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	); err != nil {
+		r.logger.Error(err)
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepo) GetByHash(keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+
+	query := `
+	SELECT
+		id,
+		user_id,
+		name,
+		prefix,
+		key_hash,
+		scopes,
+		expires_at,
+		last_used_at,
+		revoked_at,
+		created_at
+	FROM api_keys
+	WHERE key_hash = $1;`
+
+	if err := r.db.QueryRow(query, keyHash).Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKey{}, err
+		}
+		r.logger.Error(err)
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepo) GetListByUser(userId uuid.UUID) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+
+	query := `
+	SELECT
+		id,
+		user_id,
+		name,
+		prefix,
+		key_hash,
+		scopes,
+		expires_at,
+		last_used_at,
+		revoked_at,
+		created_at
+	FROM api_keys
+	WHERE user_id = $1
+	ORDER BY created_at DESC;`
+
+	rows, err := r.db.Query(query, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key models.APIKey
+		if err = rows.Scan(
+			&key.Id,
+			&key.UserId,
+			&key.Name,
+			&key.Prefix,
+			&key.KeyHash,
+			pq.Array(&key.Scopes),
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Revoke disables an active key of the user. Keys of other users are left
+// untouched and reported as missing.
+func (r *apiKeyRepo) Revoke(id, userId uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;`
+
+	row, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) RevokeByUser(userId uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+
+	if _, err := r.db.Exec(query, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) UpdateLastUsed(id uuid.UUID) error {
+	query := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1;`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type auditLogRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewAuditLogRepo(db *sqlx.DB, logger *logger.Logger) *auditLogRepo {
+	return &auditLogRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *auditLogRepo) Create(request models.CreateAuditLog) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO audit_logs (
+		id,
+		action,
+		actor_id,
+		user_id,
+		ip_address,
+		details
+	) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''));`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.Action,
+		request.ActorId,
+		request.UserId,
+		request.IPAddress,
+		request.Details,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
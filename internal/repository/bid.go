@@ -4,11 +4,13 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"tender-bridge/config"
 	"tender-bridge/internal/models"
 	"tender-bridge/pkg/logger"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type bidRepo struct {
@@ -23,28 +25,40 @@ func NewBidRepo(db *sqlx.DB, logger *logger.Logger) *bidRepo {
 	}
 }
 
+// Create stores the bid. The price, delivery time and comment of a sealed
+// bid are only kept in its sealed data.
 func (r *bidRepo) Create(request models.CreateBid) (uuid.UUID, error) {
 	id := uuid.New()
 
+	var price, deliveryTime, comment any = request.Price, request.DeliveryTime, request.Comment
+	if request.SealedData != "" {
+		price, deliveryTime, comment = nil, nil, nil
+	}
+
 	query := `
 	INSERT INTO bids (
 		id,
+		organization_id,
 		contractor_id,
 		tender_id,
 		price,
 		delivery_time,
 		comment,
-		status
-	) VALUES ($1, $2, $3, $4, $5, $6, $7);`
+		status,
+		sealed_data,
+		acknowledged_revision
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), (SELECT revision FROM tenders WHERE id = $4));`
 
 	if _, err := r.db.Exec(query,
 		id,
+		request.OrganizationId,
 		request.ContractorId,
 		request.TenderId,
-		request.Price,
-		request.DeliveryTime,
-		request.Comment,
+		price,
+		deliveryTime,
+		comment,
 		request.Status,
+		request.SealedData,
 	); err != nil {
 		r.logger.Error(err)
 		return uuid.Nil, err
@@ -53,16 +67,30 @@ func (r *bidRepo) Create(request models.CreateBid) (uuid.UUID, error) {
 	return id, nil
 }
 
+// GetList lists the bids matching the filter. Searches use the full text
+// index on the comment and return the best matches first.
 func (r *bidRepo) GetList(filter models.BidFilter) ([]models.Bid, int, error) {
+	rank, snippet := "0", "''"
+	if filter.Search != "" {
+		rank = "ts_rank(search_vector, websearch_to_tsquery('english', :search))"
+		snippet = searchHeadline("comment")
+	}
+
 	baseQuery := `
 	SELECT 
 		id, 
+		organization_id,
 		contractor_id,
 		tender_id,
-		price,
-		delivery_time,
-		comment,
-		status
+		COALESCE(price, 0),
+		COALESCE(delivery_time, 0),
+		COALESCE(comment, ''),
+		status,
+		COALESCE(sealed_data, ''),
+		acknowledged_revision,
+		created_at,
+		` + rank + ` AS rank,
+		` + snippet + ` AS snippet
 	FROM bids WHERE TRUE `
 
 	countQuery := `SELECT COUNT(*) FROM bids WHERE TRUE `
@@ -76,8 +104,9 @@ func (r *bidRepo) GetList(filter models.BidFilter) ([]models.Bid, int, error) {
 
 	// Add search condition
 	if filter.Search != "" {
-		conditions = append(conditions, "comment ILIKE :search")
-		params["search"] = "%" + filter.Search + "%"
+		conditions = append(conditions, "search_vector @@ websearch_to_tsquery('english', :search)")
+		params["search"] = filter.Search
+		params["headline_options"] = searchHeadlineOptions
 	}
 
 	if filter.FromPrice > 0 {
@@ -100,6 +129,11 @@ func (r *bidRepo) GetList(filter models.BidFilter) ([]models.Bid, int, error) {
 		params["contractor_id"] = filter.ContractorId
 	}
 
+	if filter.OrganizationIds != nil {
+		conditions = append(conditions, "organization_id = ANY(:organization_ids)")
+		params["organization_ids"] = pq.Array(filter.OrganizationIds)
+	}
+
 	// Add WHERE clause if conditions exist
 	if len(conditions) > 0 {
 		whereClause := " AND " + strings.Join(conditions, " AND ")
@@ -107,6 +141,21 @@ func (r *bidRepo) GetList(filter models.BidFilter) ([]models.Bid, int, error) {
 		countQuery += whereClause
 	}
 
+	// bids are listed newest first, search results by relevance. The cursor
+	// picks the page, the count covers the whole list.
+	if filter.Search != "" {
+		baseQuery += " ORDER BY rank DESC, created_at DESC, id DESC"
+	} else {
+		condition, orderBy := keysetPage("created_at", true, filter.Cursor)
+		if condition != "" {
+			baseQuery += " AND " + condition
+			params["cursor_value"] = filter.Cursor.Value
+			params["cursor_id"] = filter.Cursor.Id
+		}
+
+		baseQuery += " ORDER BY " + orderBy
+	}
+
 	// Add pagination
 	baseQuery += " LIMIT :limit OFFSET :offset"
 
@@ -123,16 +172,23 @@ func (r *bidRepo) GetList(filter models.BidFilter) ([]models.Bid, int, error) {
 		var bid models.Bid
 		if err := rows.Scan(
 			&bid.Id,
+			&bid.OrganizationId,
 			&bid.ContractorId,
 			&bid.TenderId,
 			&bid.Price,
 			&bid.DeliveryTime,
 			&bid.Comment,
 			&bid.Status,
+			&bid.SealedData,
+			&bid.AcknowledgedRevision,
+			&bid.CreatedAt,
+			&bid.Rank,
+			&bid.Snippet,
 		); err != nil {
 			r.logger.Error(err)
 			return nil, 0, err
 		}
+		bid.Sealed = bid.SealedData != ""
 		bids = append(bids, bid)
 	}
 
@@ -159,22 +215,30 @@ func (r *bidRepo) GetById(id uuid.UUID) (models.Bid, error) {
 	query := `
 	SELECT 
 		id, 
+		organization_id,
 		contractor_id,
 		tender_id,
-		price,
-		delivery_time,
-		comment,
-		status
+		COALESCE(price, 0),
+		COALESCE(delivery_time, 0),
+		COALESCE(comment, ''),
+		status,
+		COALESCE(sealed_data, ''),
+		acknowledged_revision,
+		created_at
 	FROM bids WHERE id = $1;`
 
 	if err := r.db.QueryRow(query, id).Scan(
 		&bid.Id,
+		&bid.OrganizationId,
 		&bid.ContractorId,
 		&bid.TenderId,
 		&bid.Price,
 		&bid.DeliveryTime,
 		&bid.Comment,
 		&bid.Status,
+		&bid.SealedData,
+		&bid.AcknowledgedRevision,
+		&bid.CreatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Bid{}, err
@@ -182,6 +246,7 @@ func (r *bidRepo) GetById(id uuid.UUID) (models.Bid, error) {
 		r.logger.Error(err)
 		return models.Bid{}, err
 	}
+	bid.Sealed = bid.SealedData != ""
 
 	return bid, nil
 }
@@ -248,3 +313,138 @@ func (r *bidRepo) Delete(id uuid.UUID) error {
 
 	return nil
 }
+
+func (r *bidRepo) GetSealed(tenderId uuid.UUID) ([]models.Bid, error) {
+	bids := []models.Bid{}
+
+	query := `
+	SELECT
+		id,
+		organization_id,
+		contractor_id,
+		tender_id,
+		status,
+		sealed_data
+	FROM bids WHERE tender_id = $1 AND sealed_data IS NOT NULL;`
+
+	rows, err := r.db.Query(query, tenderId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bid models.Bid
+		if err = rows.Scan(
+			&bid.Id,
+			&bid.OrganizationId,
+			&bid.ContractorId,
+			&bid.TenderId,
+			&bid.Status,
+			&bid.SealedData,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		bid.Sealed = true
+
+		bids = append(bids, bid)
+	}
+
+	return bids, nil
+}
+
+// Unseal stores the decrypted contents of the tender's bids and marks its
+// bids as opened. It reports false when they were already opened.
+func (r *bidRepo) Unseal(tenderId uuid.UUID, bids []models.UnsealedBid) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	row, err := tx.Exec(`UPDATE tenders SET bids_opened_at = NOW() WHERE id = $1 AND bids_opened_at IS NULL;`, tenderId)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if rowAffected == 0 {
+		return false, nil
+	}
+
+	query := `
+	UPDATE bids
+	SET
+		price = $3,
+		delivery_time = $4,
+		comment = $5,
+		sealed_data = NULL
+	WHERE id = $1 AND tender_id = $2;`
+
+	for _, bid := range bids {
+		if _, err = tx.Exec(query,
+			bid.Id,
+			tenderId,
+			bid.Price,
+			bid.DeliveryTime,
+			bid.Comment,
+		); err != nil {
+			r.logger.Error(err)
+			return false, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetBidderIds returns the contractors with a pending bid on the tender
+func (r *bidRepo) GetBidderIds(tenderId uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+
+	query := `SELECT DISTINCT contractor_id FROM bids WHERE tender_id = $1 AND status = $2;`
+
+	if err := r.db.Select(&ids, query, tenderId, config.BidStatusPending); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Acknowledge records that the bidder has seen the tender amendments up to
+// the given revision
+func (r *bidRepo) Acknowledge(id uuid.UUID, revision int) error {
+	query := `UPDATE bids SET acknowledged_revision = GREATEST(acknowledged_revision, $2) WHERE id = $1;`
+
+	row, err := r.db.Exec(query, id, revision)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
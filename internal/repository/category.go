@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type categoryRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewCategoryRepo(db *sqlx.DB, logger *logger.Logger) *categoryRepo {
+	return &categoryRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *categoryRepo) Create(request models.CreateCategory) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `INSERT INTO categories (id, parent_id, name, slug) VALUES ($1, $2, $3, $4);`
+
+	if _, err := r.db.Exec(query, id, request.ParentId, request.Name, request.Slug); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *categoryRepo) GetById(id uuid.UUID) (models.Category, error) {
+	var category models.Category
+
+	query := `SELECT id, parent_id, name, slug, created_at FROM categories WHERE id = $1;`
+
+	if err := r.db.QueryRow(query, id).Scan(
+		&category.Id,
+		&category.ParentId,
+		&category.Name,
+		&category.Slug,
+		&category.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Category{}, err
+		}
+		r.logger.Error(err)
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepo) GetAll() ([]models.Category, error) {
+	query := `SELECT id, parent_id, name, slug, created_at FROM categories ORDER BY name;`
+
+	return r.list(query)
+}
+
+func (r *categoryRepo) Delete(id uuid.UUID) error {
+	row, err := r.db.Exec(`DELETE FROM categories WHERE id = $1;`, id)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *categoryRepo) Subscribe(userId, categoryId uuid.UUID) error {
+	query := `
+	INSERT INTO category_subscriptions (user_id, category_id)
+	VALUES ($1, $2)
+	ON CONFLICT (user_id, category_id) DO NOTHING;`
+
+	if _, err := r.db.Exec(query, userId, categoryId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *categoryRepo) Unsubscribe(userId, categoryId uuid.UUID) error {
+	row, err := r.db.Exec(`DELETE FROM category_subscriptions WHERE user_id = $1 AND category_id = $2;`, userId, categoryId)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *categoryRepo) GetSubscriptions(userId uuid.UUID) ([]models.Category, error) {
+	query := `
+	SELECT
+		c.id,
+		c.parent_id,
+		c.name,
+		c.slug,
+		c.created_at
+	FROM category_subscriptions s
+	JOIN categories c ON c.id = s.category_id
+	WHERE s.user_id = $1
+	ORDER BY c.name;`
+
+	return r.list(query, userId)
+}
+
+// GetSubscriberIds returns the users subscribed to the category or to any
+// of its parents
+func (r *categoryRepo) GetSubscriberIds(categoryId uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+
+	query := `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id FROM categories WHERE id = $1
+		UNION ALL
+		SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+	)
+	SELECT DISTINCT user_id FROM category_subscriptions
+	WHERE category_id IN (SELECT id FROM ancestors);`
+
+	if err := r.db.Select(&ids, query, categoryId); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *categoryRepo) list(query string, args ...any) ([]models.Category, error) {
+	categories := []models.Category{}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category models.Category
+		if err = rows.Scan(
+			&category.Id,
+			&category.ParentId,
+			&category.Name,
+			&category.Slug,
+			&category.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
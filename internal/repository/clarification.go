@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type clarificationRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewClarificationRepo(db *sqlx.DB, logger *logger.Logger) *clarificationRepo {
+	return &clarificationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *clarificationRepo) Create(request models.CreateClarification) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO tender_clarifications (
+		id,
+		tender_id,
+		organization_id,
+		asked_by,
+		question
+	) VALUES ($1, $2, $3, $4, $5);`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.TenderId,
+		request.OrganizationId,
+		request.AskedBy,
+		request.Question,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *clarificationRepo) GetById(id uuid.UUID) (models.Clarification, error) {
+	var clarification models.Clarification
+
+	query := `
+	SELECT
+		id,
+		tender_id,
+		organization_id,
+		asked_by,
+		question,
+		answer,
+		answered_at,
+		created_at
+	FROM tender_clarifications WHERE id = $1;`
+
+	if err := r.db.QueryRow(query, id).Scan(
+		&clarification.Id,
+		&clarification.TenderId,
+		&clarification.OrganizationId,
+		&clarification.AskedBy,
+		&clarification.Question,
+		&clarification.Answer,
+		&clarification.AnsweredAt,
+		&clarification.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Clarification{}, err
+		}
+		r.logger.Error(err)
+		return models.Clarification{}, err
+	}
+
+	return clarification, nil
+}
+
+func (r *clarificationRepo) GetList(filter models.ClarificationFilter) ([]models.Clarification, error) {
+	clarifications := []models.Clarification{}
+
+	query := `
+	SELECT
+		id,
+		tender_id,
+		organization_id,
+		asked_by,
+		question,
+		answer,
+		answered_at,
+		created_at
+	FROM tender_clarifications
+	WHERE tender_id = $1 AND ($2 OR answer IS NOT NULL OR organization_id = ANY($3))
+	ORDER BY created_at;`
+
+	rows, err := r.db.Query(query, filter.TenderId, filter.All, pq.Array(filter.OrganizationIds))
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var clarification models.Clarification
+		if err = rows.Scan(
+			&clarification.Id,
+			&clarification.TenderId,
+			&clarification.OrganizationId,
+			&clarification.AskedBy,
+			&clarification.Question,
+			&clarification.Answer,
+			&clarification.AnsweredAt,
+			&clarification.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		clarifications = append(clarifications, clarification)
+	}
+
+	return clarifications, nil
+}
+
+// Answer stores the answer unless the question was answered already
+func (r *clarificationRepo) Answer(request models.AnswerClarification) error {
+	query := `
+	UPDATE tender_clarifications
+	SET
+		answer = $3,
+		answered_by = $4,
+		answered_at = NOW()
+	WHERE id = $1 AND tender_id = $2 AND answer IS NULL;`
+
+	row, err := r.db.Exec(query,
+		request.Id,
+		request.TenderId,
+		request.Answer,
+		request.AnsweredBy,
+	)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
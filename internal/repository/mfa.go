@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type mfaRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewMFARepo(db *sqlx.DB, logger *logger.Logger) *mfaRepo {
+	return &mfaRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveSecret stores a pending, not yet confirmed, TOTP secret for the user,
+// replacing any earlier pending enrollment.
+func (r *mfaRepo) SaveSecret(userId uuid.UUID, secret string) error {
+	query := `
+	INSERT INTO user_mfa (
+		user_id,
+		totp_secret
+	) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE
+	SET
+		totp_secret = EXCLUDED.totp_secret,
+		enabled_at = NULL,
+		created_at = NOW();`
+
+	if _, err := r.db.Exec(query, userId, secret); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) GetByUserId(userId uuid.UUID) (models.UserMFA, error) {
+	var mfa models.UserMFA
+
+	query := `
+	SELECT
+		user_id,
+		totp_secret,
+		enabled_at,
+		created_at
+	FROM user_mfa
+	WHERE user_id = $1;`
+
+	if err := r.db.QueryRow(query, userId).Scan(
+		&mfa.UserId,
+		&mfa.TotpSecret,
+		&mfa.EnabledAt,
+		&mfa.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserMFA{}, err
+		}
+		r.logger.Error(err)
+		return models.UserMFA{}, err
+	}
+
+	return mfa, nil
+}
+
+// Enable confirms the pending secret and replaces the recovery codes in one
+// transaction, so the user never ends up with MFA on and no way to recover.
+func (r *mfaRepo) Enable(userId uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	row, err := tx.Exec(`UPDATE user_mfa SET enabled_at = NOW() WHERE user_id = $1 AND enabled_at IS NULL;`, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	if _, err = tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1;`, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err = tx.Exec(`INSERT INTO mfa_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3);`,
+			uuid.New(),
+			userId,
+			hash,
+		); err != nil {
+			r.logger.Error(err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) Delete(userId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1;`, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1;`, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes a matching unused recovery code and reports
+// whether one was found.
+func (r *mfaRepo) UseRecoveryCode(userId uuid.UUID, codeHash string) (bool, error) {
+	query := `
+	UPDATE mfa_recovery_codes
+	SET used_at = NOW()
+	WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;`
+
+	row, err := r.db.Exec(query, userId, codeHash)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return rowAffected > 0, nil
+}
@@ -0,0 +1,374 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type organizationRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewOrganizationRepo(db *sqlx.DB, logger *logger.Logger) *organizationRepo {
+	return &organizationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts the organization together with its first owner, so an
+// organization never exists without someone able to manage it.
+func (r *organizationRepo) Create(request models.CreateOrganization, ownerId uuid.UUID) (uuid.UUID, error) {
+	id := uuid.New()
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`INSERT INTO organizations (id, name) VALUES ($1, $2);`, id, request.Name); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	if _, err = tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3);`,
+		id,
+		ownerId,
+		config.OrgRoleOwner,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *organizationRepo) GetById(id uuid.UUID) (models.Organization, error) {
+	var organization models.Organization
+
+	query := `
+	SELECT
+		id,
+		name,
+		created_at
+	FROM organizations
+	WHERE id = $1;`
+
+	if err := r.db.QueryRow(query, id).Scan(
+		&organization.Id,
+		&organization.Name,
+		&organization.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, err
+		}
+		r.logger.Error(err)
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// GetListByUser returns the organizations the user belongs to, oldest
+// membership first, with Role set to the user's role in each of them.
+func (r *organizationRepo) GetListByUser(userId uuid.UUID) ([]models.Organization, error) {
+	organizations := []models.Organization{}
+
+	query := `
+	SELECT
+		o.id,
+		o.name,
+		m.role,
+		o.created_at
+	FROM organizations o
+	JOIN organization_members m ON m.organization_id = o.id
+	WHERE m.user_id = $1
+	ORDER BY m.created_at;`
+
+	rows, err := r.db.Query(query, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var organization models.Organization
+		if err = rows.Scan(
+			&organization.Id,
+			&organization.Name,
+			&organization.Role,
+			&organization.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		organizations = append(organizations, organization)
+	}
+
+	return organizations, nil
+}
+
+func (r *organizationRepo) GetMember(organizationId, userId uuid.UUID) (models.OrganizationMember, error) {
+	var member models.OrganizationMember
+
+	query := `
+	SELECT
+		m.organization_id,
+		m.user_id,
+		u.username,
+		u.email,
+		m.role,
+		m.created_at
+	FROM organization_members m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.organization_id = $1 AND m.user_id = $2;`
+
+	if err := r.db.QueryRow(query, organizationId, userId).Scan(
+		&member.OrganizationId,
+		&member.UserId,
+		&member.Username,
+		&member.Email,
+		&member.Role,
+		&member.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrganizationMember{}, err
+		}
+		r.logger.Error(err)
+		return models.OrganizationMember{}, err
+	}
+
+	return member, nil
+}
+
+func (r *organizationRepo) GetMembers(organizationId uuid.UUID) ([]models.OrganizationMember, error) {
+	members := []models.OrganizationMember{}
+
+	query := `
+	SELECT
+		m.organization_id,
+		m.user_id,
+		u.username,
+		u.email,
+		m.role,
+		m.created_at
+	FROM organization_members m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.organization_id = $1
+	ORDER BY m.created_at;`
+
+	rows, err := r.db.Query(query, organizationId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err = rows.Scan(
+			&member.OrganizationId,
+			&member.UserId,
+			&member.Username,
+			&member.Email,
+			&member.Role,
+			&member.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepo) CountOwners(organizationId uuid.UUID) (int, error) {
+	var count int
+
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2;`
+
+	if err := r.db.Get(&count, query, organizationId, config.OrgRoleOwner); err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *organizationRepo) UpdateMemberRole(organizationId, userId uuid.UUID, role string) error {
+	query := `UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2;`
+
+	row, err := r.db.Exec(query, organizationId, userId, role)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *organizationRepo) DeleteMember(organizationId, userId uuid.UUID) error {
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2;`
+
+	row, err := r.db.Exec(query, organizationId, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *organizationRepo) CreateInvitation(request models.CreateOrganizationInvitation) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO organization_invitations (
+		id,
+		organization_id,
+		email,
+		role,
+		token_hash,
+		invited_by,
+		expires_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.OrganizationId,
+		request.Email,
+		request.Role,
+		request.TokenHash,
+		request.InvitedBy,
+		request.ExpiresAt,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *organizationRepo) GetInvitationByHash(tokenHash string) (models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+
+	query := `
+	SELECT
+		id,
+		organization_id,
+		email,
+		role,
+		token_hash,
+		invited_by,
+		expires_at,
+		accepted_at,
+		created_at
+	FROM organization_invitations
+	WHERE token_hash = $1;`
+
+	if err := r.db.QueryRow(query, tokenHash).Scan(
+		&invitation.Id,
+		&invitation.OrganizationId,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrganizationInvitation{}, err
+		}
+		r.logger.Error(err)
+		return models.OrganizationInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation marks the invitation as used and adds the user to the
+// organization in one transaction. It reports false when the invitation had
+// already been accepted. Users who are already members keep their role.
+func (r *organizationRepo) AcceptInvitation(invitation models.OrganizationInvitation, userId uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	row, err := tx.Exec(`UPDATE organization_invitations SET accepted_at = NOW() WHERE id = $1 AND accepted_at IS NULL;`, invitation.Id)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if rowAffected == 0 {
+		return false, nil
+	}
+
+	query := `
+	INSERT INTO organization_members (
+		organization_id,
+		user_id,
+		role
+	) VALUES ($1, $2, $3)
+	ON CONFLICT (organization_id, user_id) DO NOTHING;`
+
+	if _, err = tx.Exec(query, invitation.OrganizationId, userId, invitation.Role); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+	"tender-bridge/internal/models"
+)
+
+// keysetPage returns the condition selecting the rows after the cursor and
+// the ORDER BY clause of a list sorted by column and then id. The condition
+// is empty without a cursor. Backward cursors select the rows before the
+// cursor, nearest first, and the caller restores the order.
+func keysetPage(column string, desc bool, cursor *models.Cursor) (string, string) {
+	backward := cursor != nil && cursor.Backward
+
+	direction, operator := "ASC", ">"
+	if desc != backward {
+		direction, operator = "DESC", "<"
+	}
+
+	orderBy := fmt.Sprintf("%s %s, id %s", column, direction, direction)
+	if cursor == nil {
+		return "", orderBy
+	}
+
+	return fmt.Sprintf("(%s, id) %s (:cursor_value, :cursor_id)", column, operator), orderBy
+}
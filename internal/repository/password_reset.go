@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type passwordResetRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewPasswordResetRepo(db *sqlx.DB, logger *logger.Logger) *passwordResetRepo {
+	return &passwordResetRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *passwordResetRepo) Create(request models.CreatePasswordResetToken) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO password_reset_tokens (
+		id,
+		user_id,
+		token_hash,
+		expires_at
+	) VALUES ($1, $2, $3, $4);`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.UserId,
+		request.TokenHash,
+		request.ExpiresAt,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *passwordResetRepo) GetByHash(tokenHash string) (models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+
+	query := `
+	SELECT
+		id,
+		user_id,
+		token_hash,
+		expires_at,
+		used_at,
+		created_at
+	FROM password_reset_tokens
+	WHERE token_hash = $1;`
+
+	if err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PasswordResetToken{}, err
+		}
+		r.logger.Error(err)
+		return models.PasswordResetToken{}, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token and reports whether this call did so
+func (r *passwordResetRepo) MarkUsed(id uuid.UUID) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL;`
+
+	row, err := r.db.Exec(query, id)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return rowAffected > 0, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type refreshTokenRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewRefreshTokenRepo(db *sqlx.DB, logger *logger.Logger) *refreshTokenRepo {
+	return &refreshTokenRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *refreshTokenRepo) Create(request models.CreateRefreshToken) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO refresh_tokens (
+		id,
+		family_id,
+		user_id,
+		token_hash,
+		expires_at
+	) VALUES ($1, $2, $3, $4, $5);`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.FamilyId,
+		request.UserId,
+		request.TokenHash,
+		request.ExpiresAt,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *refreshTokenRepo) GetByHash(tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+
+	query := `
+	SELECT
+		id,
+		family_id,
+		user_id,
+		token_hash,
+		expires_at,
+		created_at,
+		revoked_at
+	FROM refresh_tokens
+	WHERE token_hash = $1;`
+
+	if err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.Id,
+		&token.FamilyId,
+		&token.UserId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.RevokedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, err
+		}
+		r.logger.Error(err)
+		return models.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+// Revoke marks a single token as used and reports whether this call did so.
+// Only active tokens are updated, so two concurrent rotations of the same
+// token cannot both succeed.
+func (r *refreshTokenRepo) Revoke(id uuid.UUID) (bool, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL;`
+
+	row, err := r.db.Exec(query, id)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return rowAffected > 0, nil
+}
+
+func (r *refreshTokenRepo) RevokeFamily(familyId uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL;`
+
+	if _, err := r.db.Exec(query, familyId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepo) RevokeByUser(userId uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+
+	if _, err := r.db.Exec(query, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"tender-bridge/internal/models"
 	"tender-bridge/pkg/logger"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -12,6 +13,17 @@ type Repository struct {
 	User
 	Tender
 	Bid
+	RefreshToken
+	PasswordReset
+	EmailVerification
+	MFA
+	Organization
+	APIKey
+	Identity
+	AuditLog
+	Session
+	Clarification
+	Category
 }
 
 func NewRepository(db *sqlx.DB, logger *logger.Logger) *Repository {
@@ -19,6 +31,19 @@ func NewRepository(db *sqlx.DB, logger *logger.Logger) *Repository {
 		User:   NewUserRepo(db, logger),
 		Tender: NewTenderRepo(db, logger),
 		Bid:    NewBidRepo(db, logger),
+
+		RefreshToken:  NewRefreshTokenRepo(db, logger),
+		PasswordReset: NewPasswordResetRepo(db, logger),
+
+		EmailVerification: NewEmailVerificationRepo(db, logger),
+		MFA:               NewMFARepo(db, logger),
+		Organization:      NewOrganizationRepo(db, logger),
+		APIKey:            NewAPIKeyRepo(db, logger),
+		Identity:          NewIdentityRepo(db, logger),
+		AuditLog:          NewAuditLogRepo(db, logger),
+		Session:           NewSessionRepo(db, logger),
+		Clarification:     NewClarificationRepo(db, logger),
+		Category:          NewCategoryRepo(db, logger),
 	}
 }
 
@@ -27,6 +52,11 @@ type User interface {
 	GetList(filter models.UserFilter) ([]models.User, int, error)
 	GetById(id uuid.UUID) (models.User, error)
 	Update(request models.UpdateUser) error
+	UpdateProfile(id uuid.UUID, username, email string) error
+	UpdatePassword(id uuid.UUID, password string) error
+	MarkEmailVerified(id uuid.UUID) error
+	UpdateRole(id uuid.UUID, role string) error
+	SetSuspended(id uuid.UUID, suspended bool) error
 	Delete(id uuid.UUID) error
 	GetByUsername(username string) (models.User, error)
 	GetByEmail(email string) (models.User, error)
@@ -37,9 +67,14 @@ type Tender interface {
 	Create(request models.CreateTender) (uuid.UUID, error)
 	GetList(filter models.TenderFilter) ([]models.Tender, int, error)
 	GetById(id uuid.UUID) (models.Tender, error)
-	Update(request models.UpdateTender) error
+	Amend(request models.CreateTenderRevision) (models.TenderRevision, bool, error)
+	GetRevisions(tenderId uuid.UUID) ([]models.TenderRevision, error)
 	Delete(id uuid.UUID) error
 	GetByIds(ids []uuid.UUID) ([]models.Tender, error)
+	Transition(t models.TenderTransition) (bool, error)
+	Award(t models.TenderTransition, bidId uuid.UUID) (bool, error)
+	GetHistory(tenderId uuid.UUID) ([]models.TenderStatusChange, error)
+	CloseExpired(now time.Time) ([]models.Tender, error)
 }
 
 type Bid interface {
@@ -48,4 +83,94 @@ type Bid interface {
 	GetById(id uuid.UUID) (models.Bid, error)
 	Update(request models.UpdateBid) error
 	Delete(id uuid.UUID) error
+	GetSealed(tenderId uuid.UUID) ([]models.Bid, error)
+	Unseal(tenderId uuid.UUID, bids []models.UnsealedBid) (bool, error)
+	GetBidderIds(tenderId uuid.UUID) ([]uuid.UUID, error)
+	Acknowledge(id uuid.UUID, revision int) error
+}
+
+type RefreshToken interface {
+	Create(request models.CreateRefreshToken) (uuid.UUID, error)
+	GetByHash(tokenHash string) (models.RefreshToken, error)
+	Revoke(id uuid.UUID) (bool, error)
+	RevokeFamily(familyId uuid.UUID) error
+	RevokeByUser(userId uuid.UUID) error
+}
+
+type PasswordReset interface {
+	Create(request models.CreatePasswordResetToken) (uuid.UUID, error)
+	GetByHash(tokenHash string) (models.PasswordResetToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+}
+
+type EmailVerification interface {
+	Create(request models.CreateEmailVerificationToken) (uuid.UUID, error)
+	GetByHash(tokenHash string) (models.EmailVerificationToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+}
+
+type MFA interface {
+	SaveSecret(userId uuid.UUID, secret string) error
+	GetByUserId(userId uuid.UUID) (models.UserMFA, error)
+	Enable(userId uuid.UUID, recoveryCodeHashes []string) error
+	Delete(userId uuid.UUID) error
+	UseRecoveryCode(userId uuid.UUID, codeHash string) (bool, error)
+}
+
+type Organization interface {
+	Create(request models.CreateOrganization, ownerId uuid.UUID) (uuid.UUID, error)
+	GetById(id uuid.UUID) (models.Organization, error)
+	GetListByUser(userId uuid.UUID) ([]models.Organization, error)
+	GetMember(organizationId, userId uuid.UUID) (models.OrganizationMember, error)
+	GetMembers(organizationId uuid.UUID) ([]models.OrganizationMember, error)
+	CountOwners(organizationId uuid.UUID) (int, error)
+	UpdateMemberRole(organizationId, userId uuid.UUID, role string) error
+	DeleteMember(organizationId, userId uuid.UUID) error
+	CreateInvitation(request models.CreateOrganizationInvitation) (uuid.UUID, error)
+	GetInvitationByHash(tokenHash string) (models.OrganizationInvitation, error)
+	AcceptInvitation(invitation models.OrganizationInvitation, userId uuid.UUID) (bool, error)
+}
+
+type APIKey interface {
+	Create(request models.CreateAPIKey) (models.APIKey, error)
+	GetByHash(keyHash string) (models.APIKey, error)
+	GetListByUser(userId uuid.UUID) ([]models.APIKey, error)
+	Revoke(id, userId uuid.UUID) error
+	RevokeByUser(userId uuid.UUID) error
+	UpdateLastUsed(id uuid.UUID) error
+}
+
+type Identity interface {
+	Create(request models.CreateUserIdentity) (uuid.UUID, error)
+	GetBySubject(provider, subject string) (models.UserIdentity, error)
+}
+
+type AuditLog interface {
+	Create(request models.CreateAuditLog) (uuid.UUID, error)
+}
+
+type Session interface {
+	Create(request models.CreateSession) error
+	GetActiveByUser(userId uuid.UUID) ([]models.Session, error)
+	Touch(id uuid.UUID, ipAddress string, expiresAt time.Time) error
+	Revoke(id, userId uuid.UUID) (bool, error)
+	RevokeByUser(userId uuid.UUID) error
+}
+
+type Clarification interface {
+	Create(request models.CreateClarification) (uuid.UUID, error)
+	GetById(id uuid.UUID) (models.Clarification, error)
+	GetList(filter models.ClarificationFilter) ([]models.Clarification, error)
+	Answer(request models.AnswerClarification) error
+}
+
+type Category interface {
+	Create(request models.CreateCategory) (uuid.UUID, error)
+	GetById(id uuid.UUID) (models.Category, error)
+	GetAll() ([]models.Category, error)
+	Delete(id uuid.UUID) error
+	Subscribe(userId, categoryId uuid.UUID) error
+	Unsubscribe(userId, categoryId uuid.UUID) error
+	GetSubscriptions(userId uuid.UUID) ([]models.Category, error)
+	GetSubscriberIds(categoryId uuid.UUID) ([]uuid.UUID, error)
 }
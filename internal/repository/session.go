@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type sessionRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewSessionRepo(db *sqlx.DB, logger *logger.Logger) *sessionRepo {
+	return &sessionRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sessionRepo) Create(request models.CreateSession) error {
+	query := `
+	INSERT INTO sessions (
+		id,
+		user_id,
+		user_agent,
+		ip_address,
+		expires_at
+	) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5);`
+
+	if _, err := r.db.Exec(query,
+		request.Id,
+		request.UserId,
+		request.UserAgent,
+		request.IPAddress,
+		request.ExpiresAt,
+	); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetActiveByUser lists the sessions of the user that are neither revoked
+// nor expired, most recently used first
+func (r *sessionRepo) GetActiveByUser(userId uuid.UUID) ([]models.Session, error) {
+	sessions := []models.Session{}
+
+	query := `
+	SELECT
+		id,
+		user_id,
+		COALESCE(user_agent, ''),
+		COALESCE(ip_address, ''),
+		expires_at,
+		created_at,
+		last_used_at,
+		revoked_at
+	FROM sessions
+	WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	ORDER BY last_used_at DESC;`
+
+	rows, err := r.db.Query(query, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session models.Session
+		if err = rows.Scan(
+			&session.Id,
+			&session.UserId,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.ExpiresAt,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.RevokedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// Touch records a refresh of the session from the given address and moves
+// its expiry along with the new refresh token
+func (r *sessionRepo) Touch(id uuid.UUID, ipAddress string, expiresAt time.Time) error {
+	query := `
+	UPDATE sessions SET
+		ip_address = COALESCE(NULLIF($2, ''), ip_address),
+		expires_at = $3,
+		last_used_at = NOW()
+	WHERE id = $1;`
+
+	if _, err := r.db.Exec(query, id, ipAddress, expiresAt); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// Revoke ends an active session of the user together with its refresh
+// tokens and reports whether this call did so
+func (r *sessionRepo) Revoke(id, userId uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	row, err := tx.Exec(`UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;`, id, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if rowAffected == 0 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL;`, id); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *sessionRepo) RevokeByUser(userId uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+
+	if _, err := r.db.Exec(query, userId); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -2,16 +2,40 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"tender-bridge/config"
 	"tender-bridge/internal/models"
 	"tender-bridge/pkg/logger"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
+// searchHeadlineOptions shapes the snippets returned with search results.
+// Matches are wrapped in <mark> tags.
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// searchHeadline returns the SQL for the search snippet of a text column.
+// The text is HTML escaped before the <mark> tags are added, so the tags are
+// the only markup in the snippet and it is safe to render as HTML.
+func searchHeadline(column string) string {
+	escaped := fmt.Sprintf(`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`, column)
+
+	return "COALESCE(ts_headline('english', " + escaped + ", websearch_to_tsquery('english', :search), :headline_options), '')"
+}
+
+// tenderSortColumns whitelists the columns tender lists can be sorted by
+var tenderSortColumns = map[string]string{
+	config.TenderSortBudget:    "budget",
+	config.TenderSortDeadline:  "deadline",
+	config.TenderSortCreatedAt: "created_at",
+}
+
 type tenderRepo struct {
 	db     *sqlx.DB
 	logger *logger.Logger
@@ -24,23 +48,36 @@ func NewTenderRepo(db *sqlx.DB, logger *logger.Logger) *tenderRepo {
 	}
 }
 
+// Create stores the tender and records its initial status in the history
 func (r *tenderRepo) Create(request models.CreateTender) (uuid.UUID, error) {
 	id := uuid.New()
 
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
 	query := `
 	INSERT INTO tenders (
 		id,
+		organization_id,
 		client_id,
 		title,
 		description,
 		deadline,
 		budget,
 		file,
-		status
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+		status,
+		sealed,
+		category_id,
+		tags
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`
 
-	if _, err := r.db.Exec(query,
+	if _, err = tx.Exec(query,
 		id,
+		request.OrganizationId,
 		request.ClientId,
 		request.Title,
 		request.Description,
@@ -48,25 +85,60 @@ func (r *tenderRepo) Create(request models.CreateTender) (uuid.UUID, error) {
 		request.Budget,
 		request.File,
 		request.Status,
+		request.Sealed,
+		request.CategoryId,
+		pq.Array(request.Tags),
 	); err != nil {
 		r.logger.Error(err)
 		return uuid.Nil, err
 	}
 
+	if err = r.insertHistory(tx, models.TenderTransition{
+		TenderId:  id,
+		To:        request.Status,
+		ChangedBy: &request.ClientId,
+	}); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
 	return id, nil
 }
 
+// GetList lists the tenders matching the filter. Searches use the full text
+// index and return the best matches first, with a highlighted snippet of
+// the description.
 func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int, error) {
+	rank, snippet := "0", "''"
+	if filter.Search != "" {
+		rank = "ts_rank(search_vector, websearch_to_tsquery('english', :search))"
+		snippet = searchHeadline("description")
+	}
+
 	baseQuery := `
 	SELECT 
 		id, 
+		organization_id,
 		client_id,
 		title,
 		description,
 		deadline,
 		budget,
 		file,
-		status 
+		status,
+		sealed,
+		bids_opened_at,
+		revision,
+		material_revision,
+		category_id,
+		tags,
+		created_at,
+		` + rank + ` AS rank,
+		` + snippet + ` AS snippet
 	FROM tenders WHERE TRUE `
 
 	countQuery := `SELECT COUNT(*) FROM tenders WHERE TRUE `
@@ -80,8 +152,9 @@ func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int,
 
 	// Add search condition
 	if filter.Search != "" {
-		conditions = append(conditions, "(title || description) ILIKE :search")
-		params["search"] = "%" + filter.Search + "%"
+		conditions = append(conditions, "search_vector @@ websearch_to_tsquery('english', :search)")
+		params["search"] = filter.Search
+		params["headline_options"] = searchHeadlineOptions
 	}
 
 	if filter.ClientId != uuid.Nil {
@@ -89,6 +162,63 @@ func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int,
 		params["client_id"] = filter.ClientId
 	}
 
+	if !filter.IncludeDrafts {
+		conditions = append(conditions, "status <> :draft")
+		params["draft"] = config.TenderStatusDraft
+	}
+
+	if filter.CategoryId != uuid.Nil {
+		conditions = append(conditions, `category_id IN (
+			WITH RECURSIVE subcategories AS (
+				SELECT id FROM categories WHERE id = :category_id
+				UNION ALL
+				SELECT c.id FROM categories c JOIN subcategories s ON c.parent_id = s.id
+			)
+			SELECT id FROM subcategories
+		)`)
+		params["category_id"] = filter.CategoryId
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, "tags @> :tags")
+		params["tags"] = pq.Array(filter.Tags)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = :status")
+		params["status"] = filter.Status
+	}
+
+	if filter.MinBudget != nil {
+		conditions = append(conditions, "budget >= :min_budget")
+		params["min_budget"] = *filter.MinBudget
+	}
+
+	if filter.MaxBudget != nil {
+		conditions = append(conditions, "budget <= :max_budget")
+		params["max_budget"] = *filter.MaxBudget
+	}
+
+	if filter.DeadlineAfter != nil {
+		conditions = append(conditions, "deadline >= :deadline_after")
+		params["deadline_after"] = *filter.DeadlineAfter
+	}
+
+	if filter.DeadlineBefore != nil {
+		conditions = append(conditions, "deadline <= :deadline_before")
+		params["deadline_before"] = *filter.DeadlineBefore
+	}
+
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= :created_after")
+		params["created_after"] = *filter.CreatedAfter
+	}
+
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at <= :created_before")
+		params["created_before"] = *filter.CreatedBefore
+	}
+
 	// Add WHERE clause if conditions exist
 	if len(conditions) > 0 {
 		whereClause := " AND " + strings.Join(conditions, " AND ")
@@ -96,6 +226,16 @@ func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int,
 		countQuery += whereClause
 	}
 
+	// the cursor picks the page, the count covers the whole list
+	condition, orderBy := tenderPage(filter)
+	if condition != "" {
+		baseQuery += " AND " + condition
+		params["cursor_value"] = filter.Cursor.Value
+		params["cursor_id"] = filter.Cursor.Id
+	}
+
+	baseQuery += " ORDER BY " + orderBy
+
 	// Add pagination
 	baseQuery += " LIMIT :limit OFFSET :offset"
 
@@ -112,6 +252,7 @@ func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int,
 		var tender models.Tender
 		if err := rows.Scan(
 			&tender.Id,
+			&tender.OrganizationId,
 			&tender.ClientId,
 			&tender.Title,
 			&tender.Description,
@@ -119,6 +260,15 @@ func (r *tenderRepo) GetList(filter models.TenderFilter) ([]models.Tender, int,
 			&tender.Budget,
 			&tender.File,
 			&tender.Status,
+			&tender.Sealed,
+			&tender.BidsOpenedAt,
+			&tender.Revision,
+			&tender.MaterialRevision,
+			&tender.CategoryId,
+			pq.Array(&tender.Tags),
+			&tender.CreatedAt,
+			&tender.Rank,
+			&tender.Snippet,
 		); err != nil {
 			r.logger.Error(err)
 			return nil, 0, err
@@ -149,17 +299,26 @@ func (r *tenderRepo) GetById(id uuid.UUID) (models.Tender, error) {
 	query := `
 	SELECT 
 		id, 
+		organization_id,
 		client_id,
 		title,
 		description,
 		deadline,
 		budget,
 		file,
-		status 
+		status,
+		sealed,
+		bids_opened_at,
+		revision,
+		material_revision,
+		category_id,
+		tags,
+		created_at
 	FROM tenders WHERE id = $1;`
 
 	if err := r.db.QueryRow(query, id).Scan(
 		&tender.Id,
+		&tender.OrganizationId,
 		&tender.ClientId,
 		&tender.Title,
 		&tender.Description,
@@ -167,6 +326,13 @@ func (r *tenderRepo) GetById(id uuid.UUID) (models.Tender, error) {
 		&tender.Budget,
 		&tender.File,
 		&tender.Status,
+		&tender.Sealed,
+		&tender.BidsOpenedAt,
+		&tender.Revision,
+		&tender.MaterialRevision,
+		&tender.CategoryId,
+		pq.Array(&tender.Tags),
+		&tender.CreatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Tender{}, err
@@ -178,46 +344,154 @@ func (r *tenderRepo) GetById(id uuid.UUID) (models.Tender, error) {
 	return tender, nil
 }
 
-func (r *tenderRepo) Update(request models.UpdateTender) error {
+// Amend applies the amendment if the tender is still a draft or published
+// at revision base and stores it as the next revision. It reports false when
+// another amendment or a status change came first.
+func (r *tenderRepo) Amend(request models.CreateTenderRevision) (models.TenderRevision, bool, error) {
+	revision := models.TenderRevision{
+		Id:        uuid.New(),
+		TenderId:  request.TenderId,
+		Number:    request.Base + 1,
+		Material:  request.Material,
+		Changes:   request.Changes,
+		Reason:    request.Reason,
+		ChangedBy: request.ChangedBy,
+	}
+
+	changes, err := json.Marshal(request.Changes)
+	if err != nil {
+		return models.TenderRevision{}, false, err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return models.TenderRevision{}, false, err
+	}
+	defer tx.Rollback()
+
 	query := `
 	UPDATE tenders
 	SET
-		client_id = $2,
 		title = $3,
 		description = $4,
 		deadline = $5,
 		budget = $6,
 		file = $7,
-		status = $8
-	WHERE id = $1;`
+		revision = revision + 1,
+		material_revision = CASE WHEN $8 THEN revision + 1 ELSE material_revision END
+	WHERE id = $1 AND revision = $2 AND status IN ('draft', 'published');`
 
-	// Execute the query
-	row, err := r.db.Exec(query,
-		request.Id,
-		request.ClientId,
+	row, err := tx.Exec(query,
+		request.TenderId,
+		request.Base,
 		request.Title,
 		request.Description,
 		request.Deadline,
 		request.Budget,
 		request.File,
-		request.Status,
+		request.Material,
 	)
 	if err != nil {
 		r.logger.Error(err)
-		return err
+		return models.TenderRevision{}, false, err
 	}
 
 	rowAffected, err := row.RowsAffected()
 	if err != nil {
 		r.logger.Error(err)
-		return err
+		return models.TenderRevision{}, false, err
 	}
 
 	if rowAffected == 0 {
-		return errNoRowsAffected
+		return models.TenderRevision{}, false, nil
 	}
 
-	return nil
+	query = `
+	INSERT INTO tender_revisions (
+		id,
+		tender_id,
+		number,
+		material,
+		changes,
+		reason,
+		changed_by
+	) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	RETURNING created_at;`
+
+	if err = tx.QueryRow(query,
+		revision.Id,
+		revision.TenderId,
+		revision.Number,
+		revision.Material,
+		changes,
+		revision.Reason,
+		revision.ChangedBy,
+	).Scan(&revision.CreatedAt); err != nil {
+		r.logger.Error(err)
+		return models.TenderRevision{}, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return models.TenderRevision{}, false, err
+	}
+
+	return revision, true, nil
+}
+
+func (r *tenderRepo) GetRevisions(tenderId uuid.UUID) ([]models.TenderRevision, error) {
+	revisions := []models.TenderRevision{}
+
+	query := `
+	SELECT
+		id,
+		tender_id,
+		number,
+		material,
+		changes,
+		COALESCE(reason, ''),
+		changed_by,
+		created_at
+	FROM tender_revisions
+	WHERE tender_id = $1
+	ORDER BY number;`
+
+	rows, err := r.db.Query(query, tenderId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			revision models.TenderRevision
+			changes  []byte
+		)
+		if err = rows.Scan(
+			&revision.Id,
+			&revision.TenderId,
+			&revision.Number,
+			&revision.Material,
+			&changes,
+			&revision.Reason,
+			&revision.ChangedBy,
+			&revision.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		if err = json.Unmarshal(changes, &revision.Changes); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
 }
 
 func (r *tenderRepo) Delete(id uuid.UUID) error {
@@ -249,13 +523,21 @@ func (r *tenderRepo) GetByIds(ids []uuid.UUID) ([]models.Tender, error) {
 	query := `
 	SELECT 
 		id, 
+		organization_id,
 		client_id,
 		title,
 		description,
 		deadline,
 		budget,
 		file,
-		status 
+		status,
+		sealed,
+		bids_opened_at,
+		revision,
+		material_revision,
+		category_id,
+		tags,
+		created_at
 	FROM tenders WHERE id = ANY($1);`
 
 	rows, err := r.db.Query(query, pq.Array(ids))
@@ -269,6 +551,7 @@ func (r *tenderRepo) GetByIds(ids []uuid.UUID) ([]models.Tender, error) {
 		var tender models.Tender
 		if err = rows.Scan(
 			&tender.Id,
+			&tender.OrganizationId,
 			&tender.ClientId,
 			&tender.Title,
 			&tender.Description,
@@ -276,13 +559,292 @@ func (r *tenderRepo) GetByIds(ids []uuid.UUID) ([]models.Tender, error) {
 			&tender.Budget,
 			&tender.File,
 			&tender.Status,
+			&tender.Sealed,
+			&tender.BidsOpenedAt,
+			&tender.Revision,
+			&tender.MaterialRevision,
+			&tender.CategoryId,
+			pq.Array(&tender.Tags),
+			&tender.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		tenders = append(tenders, tender)
+	}
+
+	return tenders, nil
+}
+
+// Transition changes the status of the tender if it is still t.From and
+// records the change. It reports false when another change came first.
+// Pending bids of a cancelled tender are closed.
+func (r *tenderRepo) Transition(t models.TenderTransition) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	changed, err := r.transition(tx, t)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	if t.To == config.TenderStatusCancelled {
+		if err = r.closePendingBids(tx, t.TenderId, uuid.Nil); err != nil {
+			return false, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Award moves the tender to awarded, marks the winning bid and closes every
+// other pending bid in one transaction. It reports false when the tender
+// status changed in the meantime.
+func (r *tenderRepo) Award(t models.TenderTransition, bidId uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	changed, err := r.transition(tx, t)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	if _, err = tx.Exec(`UPDATE bids SET status = $3 WHERE id = $1 AND tender_id = $2;`,
+		bidId,
+		t.TenderId,
+		config.BidStatusAwarded,
+	); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if err = r.closePendingBids(tx, t.TenderId, bidId); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *tenderRepo) GetHistory(tenderId uuid.UUID) ([]models.TenderStatusChange, error) {
+	history := []models.TenderStatusChange{}
+
+	query := `
+	SELECT
+		id,
+		tender_id,
+		from_status,
+		to_status,
+		changed_by,
+		COALESCE(reason, ''),
+		created_at
+	FROM tender_status_history
+	WHERE tender_id = $1
+	ORDER BY created_at;`
+
+	rows, err := r.db.Query(query, tenderId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change models.TenderStatusChange
+		if err = rows.Scan(
+			&change.Id,
+			&change.TenderId,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.ChangedBy,
+			&change.Reason,
+			&change.CreatedAt,
+		); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		history = append(history, change)
+	}
+
+	return history, nil
+}
+
+// CloseExpired closes the published tenders whose deadline is before now and
+// returns them. Only one replica does the work at a time; the others get
+// nothing back while the advisory lock is held.
+func (r *tenderRepo) CloseExpired(now time.Time) ([]models.Tender, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err = tx.Get(&locked, `SELECT pg_try_advisory_xact_lock(hashtext('close_expired_tenders'));`); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	if !locked {
+		return nil, nil
+	}
+
+	query := `
+	UPDATE tenders
+	SET status = $1
+	WHERE status = $2 AND deadline <= $3
+	RETURNING
+		id,
+		organization_id,
+		client_id,
+		title,
+		deadline,
+		status,
+		sealed,
+		bids_opened_at;`
+
+	rows, err := tx.Query(query, config.TenderStatusClosed, config.TenderStatusPublished, now)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	tenders := []models.Tender{}
+	for rows.Next() {
+		var tender models.Tender
+		if err = rows.Scan(
+			&tender.Id,
+			&tender.OrganizationId,
+			&tender.ClientId,
+			&tender.Title,
+			&tender.Deadline,
+			&tender.Status,
+			&tender.Sealed,
+			&tender.BidsOpenedAt,
 		); err != nil {
+			rows.Close()
 			r.logger.Error(err)
 			return nil, err
 		}
 
 		tenders = append(tenders, tender)
 	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	for _, tender := range tenders {
+		if err = r.insertHistory(tx, models.TenderTransition{
+			TenderId: tender.Id,
+			From:     config.TenderStatusPublished,
+			To:       config.TenderStatusClosed,
+			Reason:   "Deadline passed",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
 
 	return tenders, nil
 }
+
+func (r *tenderRepo) transition(tx *sqlx.Tx, t models.TenderTransition) (bool, error) {
+	row, err := tx.Exec(`UPDATE tenders SET status = $3 WHERE id = $1 AND status = $2;`, t.TenderId, t.From, t.To)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	if rowAffected == 0 {
+		return false, nil
+	}
+
+	return true, r.insertHistory(tx, t)
+}
+
+func (r *tenderRepo) insertHistory(tx *sqlx.Tx, t models.TenderTransition) error {
+	query := `
+	INSERT INTO tender_status_history (
+		id,
+		tender_id,
+		from_status,
+		to_status,
+		changed_by,
+		reason
+	) VALUES ($1, $2, NULLIF($3, '')::tender_status, $4, $5, NULLIF($6, ''));`
+
+	if _, err := tx.Exec(query,
+		uuid.New(),
+		t.TenderId,
+		t.From,
+		t.To,
+		t.ChangedBy,
+		t.Reason,
+	); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// closePendingBids closes the bids of the tender that are still pending,
+// except the one with id keep
+func (r *tenderRepo) closePendingBids(tx *sqlx.Tx, tenderId, keep uuid.UUID) error {
+	query := `UPDATE bids SET status = $3 WHERE tender_id = $1 AND id <> $2 AND status = $4;`
+
+	if _, err := tx.Exec(query, tenderId, keep, config.BidStatusClosed, config.BidStatusPending); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// tenderPage returns the cursor condition and the ORDER BY clause of a
+// tender list. The id breaks ties so that the order, and with it every page,
+// is deterministic. Search results ranked by relevance have no cursors.
+func tenderPage(filter models.TenderFilter) (string, string) {
+	column, ok := tenderSortColumns[filter.Sort]
+	if !ok {
+		if filter.Search != "" {
+			return "", "rank DESC, created_at DESC, id DESC"
+		}
+		return keysetPage("created_at", true, filter.Cursor)
+	}
+
+	return keysetPage(column, filter.Order == config.SortDesc, filter.Cursor)
+}
@@ -61,7 +61,9 @@ func (r *userRepo) GetList(filter models.UserFilter) ([]models.User, int, error)
 		role, 
 		username, 
 		email, 
-		password 
+		password,
+		email_verified_at,
+		suspended_at
 	FROM users WHERE TRUE `
 
 	countQuery := `SELECT COUNT(*) FROM users WHERE TRUE `
@@ -79,6 +81,11 @@ func (r *userRepo) GetList(filter models.UserFilter) ([]models.User, int, error)
 		params["search"] = "%" + filter.Search + "%"
 	}
 
+	if filter.Role != "" {
+		conditions = append(conditions, "role = :role")
+		params["role"] = filter.Role
+	}
+
 	// Add WHERE clause if conditions exist
 	if len(conditions) > 0 {
 		whereClause := " AND " + strings.Join(conditions, " AND ")
@@ -87,7 +94,7 @@ func (r *userRepo) GetList(filter models.UserFilter) ([]models.User, int, error)
 	}
 
 	// Add pagination
-	baseQuery += " LIMIT :limit OFFSET :offset"
+	baseQuery += " ORDER BY username LIMIT :limit OFFSET :offset"
 
 	// Execute the main query
 	users := []models.User{}
@@ -106,6 +113,8 @@ func (r *userRepo) GetList(filter models.UserFilter) ([]models.User, int, error)
 			&user.Username,
 			&user.Email,
 			&user.Password,
+			&user.EmailVerifiedAt,
+			&user.SuspendedAt,
 		); err != nil {
 			r.logger.Error(err)
 			return nil, 0, err
@@ -139,7 +148,9 @@ func (r *userRepo) GetById(id uuid.UUID) (models.User, error) {
 		role, 
 		username, 
 		email, 
-		password 
+		password,
+		email_verified_at,
+		suspended_at
 	FROM users 
 	WHERE id = $1;`
 
@@ -149,6 +160,8 @@ func (r *userRepo) GetById(id uuid.UUID) (models.User, error) {
 		&user.Username,
 		&user.Email,
 		&user.Password,
+		&user.EmailVerifiedAt,
+		&user.SuspendedAt,
 	); err != nil {
 		r.logger.Error(err)
 		return models.User{}, err
@@ -194,6 +207,140 @@ func (r *userRepo) Update(request models.UpdateUser) error {
 	return nil
 }
 
+// UpdateProfile sets the username and email. Changing the email clears its
+// verification and voids links already sent to the old address.
+func (r *userRepo) UpdateProfile(id uuid.UUID, username, email string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE email_verification_tokens
+	SET used_at = NOW()
+	WHERE user_id = $1 AND used_at IS NULL
+		AND EXISTS (SELECT 1 FROM users WHERE id = $1 AND email <> $2);`
+
+	if _, err = tx.Exec(query, id, email); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	query = `
+	UPDATE users
+	SET
+		username = $2,
+		email = $3,
+		email_verified_at = CASE WHEN email = $3 THEN email_verified_at END
+	WHERE
+		id = $1;`
+
+	row, err := tx.Exec(query, id, username, email)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepo) UpdatePassword(id uuid.UUID, password string) error {
+	query := `UPDATE users SET password = $2 WHERE id = $1;`
+
+	row, err := r.db.Exec(query, id, password)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *userRepo) MarkEmailVerified(id uuid.UUID) error {
+	query := `UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email_verified_at IS NULL;`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepo) UpdateRole(id uuid.UUID, role string) error {
+	query := `UPDATE users SET role = $2 WHERE id = $1;`
+
+	row, err := r.db.Exec(query, id, role)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
+func (r *userRepo) SetSuspended(id uuid.UUID, suspended bool) error {
+	query := `UPDATE users SET suspended_at = NULL WHERE id = $1;`
+	if suspended {
+		query = `UPDATE users SET suspended_at = COALESCE(suspended_at, NOW()) WHERE id = $1;`
+	}
+
+	row, err := r.db.Exec(query, id)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	rowAffected, err := row.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if rowAffected == 0 {
+		return errNoRowsAffected
+	}
+
+	return nil
+}
+
 func (r *userRepo) Delete(id uuid.UUID) error {
 	query := `DELETE FROM users WHERE id = $1;`
 
@@ -226,7 +373,9 @@ func (r *userRepo) GetByUsername(username string) (models.User, error) {
 		role, 
 		username, 
 		email, 
-		password 
+		password,
+		email_verified_at,
+		suspended_at
 	FROM users 
 	WHERE username = $1;`
 
@@ -236,6 +385,8 @@ func (r *userRepo) GetByUsername(username string) (models.User, error) {
 		&user.Username,
 		&user.Email,
 		&user.Password,
+		&user.EmailVerifiedAt,
+		&user.SuspendedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, err
@@ -256,7 +407,9 @@ func (r *userRepo) GetByEmail(email string) (models.User, error) {
 		role, 
 		username, 
 		email, 
-		password 
+		password,
+		email_verified_at,
+		suspended_at
 	FROM users 
 	WHERE email = $1;`
 
@@ -266,6 +419,8 @@ func (r *userRepo) GetByEmail(email string) (models.User, error) {
 		&user.Username,
 		&user.Email,
 		&user.Password,
+		&user.EmailVerifiedAt,
+		&user.SuspendedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, err
@@ -286,7 +441,9 @@ func (r *userRepo) GetByIds(ids []uuid.UUID) ([]models.User, error) {
 		role, 
 		username, 
 		email, 
-		password 
+		password,
+		email_verified_at,
+		suspended_at
 	FROM users 
 	WHERE id = ANY($1);`
 
@@ -305,6 +462,8 @@ func (r *userRepo) GetByIds(ids []uuid.UUID) ([]models.User, error) {
 			&user.Username,
 			&user.Email,
 			&user.Password,
+			&user.EmailVerifiedAt,
+			&user.SuspendedAt,
 		); err != nil {
 			r.logger.Error(err)
 			return nil, err
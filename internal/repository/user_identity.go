@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type identityRepo struct {
+	db     *sqlx.DB
+	logger *logger.Logger
+}
+
+func NewIdentityRepo(db *sqlx.DB, logger *logger.Logger) *identityRepo {
+	return &identityRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *identityRepo) Create(request models.CreateUserIdentity) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO user_identities (
+		id,
+		user_id,
+		provider,
+		subject,
+		email
+	) VALUES ($1, $2, $3, $4, $5);`
+
+	if _, err := r.db.Exec(query,
+		id,
+		request.UserId,
+		request.Provider,
+		request.Subject,
+		request.Email,
+	); err != nil {
+		r.logger.Error(err)
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *identityRepo) GetBySubject(provider, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+
+	query := `
+	SELECT
+		id,
+		user_id,
+		provider,
+		subject,
+		COALESCE(email, ''),
+		created_at
+	FROM user_identities
+	WHERE provider = $1 AND subject = $2;`
+
+	if err := r.db.QueryRow(query, provider, subject).Scan(
+		&identity.Id,
+		&identity.UserId,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserIdentity{}, err
+		}
+		r.logger.Error(err)
+		return models.UserIdentity{}, err
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// apiKeyPrefix marks keys issued by this service so they are easy to spot
+// in logs and secret scanners
+const apiKeyPrefix = "tb_"
+
+var (
+	errInvalidAPIKey = errors.New("error: Invalid or expired API key")
+	errInvalidScope  = errors.New("error: Invalid API key scope")
+)
+
+type apiKeyService struct {
+	repo   *repository.Repository
+	logger *logger.Logger
+}
+
+func NewAPIKeyService(repo *repository.Repository, logger *logger.Logger) *apiKeyService {
+	return &apiKeyService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey issues a new key for the subject. Scopes are policy actions
+// and can only grant what the subject's role already allows.
+func (s *apiKeyService) CreateAPIKey(subject policy.Subject, request models.CreateAPIKey) (models.CreatedAPIKey, error) {
+	scopes := make([]string, 0, len(request.Scopes))
+	seen := make(map[string]bool, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		if !policy.Can(subject.Role, policy.Action(scope)) {
+			return models.CreatedAPIKey{}, serviceError(fmt.Errorf("%w: %s", errInvalidScope, scope), codes.InvalidArgument)
+		}
+
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(time.Now()) {
+			return models.CreatedAPIKey{}, serviceError(errors.New("error: Expiry must be in the future"), codes.InvalidArgument)
+		}
+		expiresAt := request.ExpiresAt.UTC()
+		request.ExpiresAt = &expiresAt
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return models.CreatedAPIKey{}, serviceError(err, codes.Internal)
+	}
+
+	secret, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return models.CreatedAPIKey{}, serviceError(err, codes.Internal)
+	}
+
+	prefix := fmt.Sprintf("%s%x", apiKeyPrefix, prefixBytes)
+	key := prefix + "_" + secret
+
+	request.UserId = subject.Id
+	request.Scopes = scopes
+	request.Prefix = prefix
+	request.KeyHash = helper.HashToken(key)
+
+	apiKey, err := s.repo.APIKey.Create(request)
+	if err != nil {
+		return models.CreatedAPIKey{}, serviceError(err, codes.Internal)
+	}
+
+	return models.CreatedAPIKey{
+		APIKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(userId uuid.UUID) ([]models.APIKey, error) {
+	keys, err := s.repo.APIKey.GetListByUser(userId)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(userId, id uuid.UUID) error {
+	if err := s.repo.APIKey.Revoke(id, userId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey resolves a plain API key to its owner and scopes and
+// records when it was last used
+func (s *apiKeyService) AuthenticateAPIKey(key string) (models.User, []string, error) {
+	apiKey, err := s.repo.APIKey.GetByHash(helper.HashToken(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, nil, serviceError(errInvalidAPIKey, codes.Unauthenticated)
+		}
+		return models.User{}, nil, serviceError(err, codes.Internal)
+	}
+
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().UTC().After(*apiKey.ExpiresAt)) {
+		return models.User{}, nil, serviceError(errInvalidAPIKey, codes.Unauthenticated)
+	}
+
+	user, err := s.repo.User.GetById(apiKey.UserId)
+	if err != nil {
+		return models.User{}, nil, serviceError(err, codes.Internal)
+	}
+
+	if user.SuspendedAt != nil {
+		return models.User{}, nil, serviceError(errAccountSuspended, codes.PermissionDenied)
+	}
+
+	go func() {
+		if err := s.repo.APIKey.UpdateLastUsed(apiKey.Id); err != nil {
+			s.logger.Error(err)
+		}
+	}()
+
+	return user, apiKey.Scopes, nil
+}
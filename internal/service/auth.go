@@ -3,11 +3,16 @@ package service
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/mailer"
 	"tender-bridge/internal/models"
 	"tender-bridge/internal/repository"
 	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/jwks"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/validator"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -15,41 +20,67 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
+var (
+	errInvalidRefreshToken = errors.New("error: Invalid refresh token")
+	errRefreshTokenReused  = errors.New("error: Refresh token has already been used")
+	errInvalidResetToken   = errors.New("error: Invalid or expired reset token")
+	errInvalidVerifyToken  = errors.New("error: Invalid or expired verification token")
+	errAccountSuspended    = errors.New("error: Account is suspended")
+)
+
 type authService struct {
 	repo   *repository.Repository
+	cache  *cache.RedisCache
+	mailer mailer.Sender
+	keys   *jwks.KeySet
 	logger *logger.Logger
 	cfg    *config.Config
+
+	throttle  *loginThrottle
+	passwords *validator.PasswordPolicy
 }
 
-func NewAuthService(repo *repository.Repository, logger *logger.Logger, cfg *config.Config) *authService {
+func NewAuthService(repo *repository.Repository, cache *cache.RedisCache, mailer mailer.Sender, keys *jwks.KeySet, passwords *validator.PasswordPolicy, logger *logger.Logger, cfg *config.Config) *authService {
 	return &authService{
 		repo:   repo,
+		cache:  cache,
+		mailer: mailer,
+		keys:   keys,
 		logger: logger,
 		cfg:    cfg,
+
+		throttle:  newLoginThrottle(repo, cache, logger, cfg),
+		passwords: passwords,
 	}
 }
 
 type jwtCustomClaim struct {
 	jwt.StandardClaims
-	UserId uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
-	Type   string    `json:"type"`
+	UserId    uuid.UUID  `json:"user_id"`
+	Role      string     `json:"role"`
+	Type      string     `json:"type"`
+	SessionId *uuid.UUID `json:"sid,omitempty"`
 }
 
 func (s *authService) CreateToken(user models.User, tokenType string, expiresAt time.Time) (*models.Token, error) {
+	return s.createToken(user, tokenType, expiresAt, nil)
+}
+
+// createToken signs a token, tying it to a session when sessionId is set
+func (s *authService) createToken(user models.User, tokenType string, expiresAt time.Time, sessionId *uuid.UUID) (*models.Token, error) {
 	claims := &jwtCustomClaim{
-		UserId: user.Id,
-		Role:   user.Role,
-		Type:   tokenType,
+		UserId:    user.Id,
+		Role:      user.Role,
+		Type:      tokenType,
+		SessionId: sessionId,
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
 			IssuedAt:  time.Now().Unix(),
 			ExpiresAt: expiresAt.Unix(),
 		},
 	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	token, err := jwtToken.SignedString([]byte(config.GetConfig().JWTSecret))
+	token, err := s.keys.Sign(claims)
 	if err != nil {
 		return nil, serviceError(err, codes.Internal)
 	}
@@ -61,31 +92,305 @@ func (s *authService) CreateToken(user models.User, tokenType string, expiresAt
 	}, nil
 }
 
-func (s *authService) GenerateTokens(user models.User) (*models.Token, *models.Token, error) {
+// GenerateTokens starts a new session for the client and issues its first
+// token pair
+func (s *authService) GenerateTokens(user models.User, client models.Client) (*models.Token, *models.Token, error) {
+	sessionId := uuid.New()
+
+	if err := s.repo.Session.Create(models.CreateSession{
+		Id:        sessionId,
+		UserId:    user.Id,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IP,
+		ExpiresAt: time.Now().UTC().Add(s.refreshTokenTTL()),
+	}); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	return s.generateTokens(user, sessionId)
+}
+
+// generateTokens issues an access/refresh pair and persists the refresh token
+// as a member of the given family, so that a replay of any rotated-out token
+// can revoke every token descended from the same login. The family id is
+// also the id of the session both tokens belong to.
+func (s *authService) generateTokens(user models.User, familyId uuid.UUID) (*models.Token, *models.Token, error) {
 	accessExpiresAt := time.Now().Add(time.Duration(s.cfg.JWTAccessExpirationHours) * time.Hour)
-	refreshExpiresAt := time.Now().Add(time.Duration(s.cfg.JWTRefreshExpirationDays) * time.Hour * 24)
+	refreshExpiresAt := time.Now().Add(s.refreshTokenTTL())
 
-	accessToken, err := s.CreateToken(user, config.TokenTypeAccess, accessExpiresAt)
+	accessToken, err := s.createToken(user, config.TokenTypeAccess, accessExpiresAt, &familyId)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	refreshToken, err := s.CreateToken(user, config.TokenTypeRefresh, refreshExpiresAt)
+	refreshToken, err := s.createToken(user, config.TokenTypeRefresh, refreshExpiresAt, &familyId)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if _, err = s.repo.RefreshToken.Create(models.CreateRefreshToken{
+		FamilyId:  familyId,
+		UserId:    user.Id,
+		TokenHash: helper.HashToken(refreshToken.Token),
+		ExpiresAt: refreshExpiresAt,
+	}); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
 	return accessToken, refreshToken, nil
 }
 
-func (s *authService) ParseToken(token string) (*jwtCustomClaim, error) {
-	jwtToken, err := jwt.ParseWithClaims(token, &jwtCustomClaim{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+func (s *authService) refreshTokenTTL() time.Duration {
+	return time.Duration(s.cfg.JWTRefreshExpirationDays) * time.Hour * 24
+}
+
+func (s *authService) RefreshTokens(refreshToken string, client models.Client) (*models.Token, *models.Token, error) {
+	claims, err := s.ParseToken(refreshToken)
+	if err != nil || claims.Type != config.TokenTypeRefresh {
+		return nil, nil, serviceError(errInvalidRefreshToken, codes.Unauthenticated)
+	}
+
+	stored, err := s.repo.RefreshToken.GetByHash(helper.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, serviceError(errInvalidRefreshToken, codes.Unauthenticated)
+		}
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, nil, s.revokeReusedFamily(stored)
+	}
+
+	revoked, err := s.repo.RefreshToken.Revoke(stored.Id)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	// another request rotated this token first
+	if !revoked {
+		return nil, nil, s.revokeReusedFamily(stored)
+	}
+
+	user, err := s.repo.User.GetById(stored.UserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, serviceError(errInvalidRefreshToken, codes.Unauthenticated)
+		}
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, nil, serviceError(errAccountSuspended, codes.PermissionDenied)
+	}
+
+	if err = s.repo.Session.Touch(stored.FamilyId, client.IP, time.Now().UTC().Add(s.refreshTokenTTL())); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	return s.generateTokens(user, stored.FamilyId)
+}
+
+func (s *authService) Logout(accessToken, refreshToken string) error {
+	claims, err := s.ParseToken(accessToken)
+	if err != nil {
+		return serviceError(err, codes.Unauthenticated)
+	}
+
+	if ttl := time.Until(time.Unix(claims.ExpiresAt, 0)); ttl > 0 {
+		if err = s.cache.Set(tokenDenylistKey(claims.Id), true, ttl); err != nil {
+			return serviceError(err, codes.Internal)
+		}
+	}
+
+	// the access token names its session, so logging out ends it even when
+	// the client no longer has the refresh token
+	if claims.SessionId != nil {
+		if _, err = revokeSession(s.repo, s.cache, s.cfg, claims.UserId, *claims.SessionId); err != nil {
+			return serviceError(err, codes.Internal)
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.repo.RefreshToken.GetByHash(helper.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if stored.UserId != claims.UserId {
+		return nil
+	}
+
+	if _, err = revokeSession(s.repo, s.cache, s.cfg, stored.UserId, stored.FamilyId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *authService) IsTokenRevoked(claims *jwtCustomClaim) (bool, error) {
+	if claims.Id != "" {
+		denied, err := s.cache.Exists(tokenDenylistKey(claims.Id))
+		if err != nil {
+			return false, err
+		}
+
+		if denied {
+			return true, nil
+		}
+	}
+
+	if claims.SessionId != nil {
+		revoked, err := s.cache.Exists(sessionRevokedKey(*claims.SessionId))
+		if err != nil {
+			return false, err
+		}
+
+		if revoked {
+			return true, nil
+		}
+	}
+
+	var revokedBefore int64
+	if err := s.cache.Get(userTokensRevokedKey(claims.UserId), &revokedBefore); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return claims.IssuedAt < revokedBefore, nil
+}
+
+func (s *authService) RevokeUserTokens(userId uuid.UUID) error {
+	if _, err := s.repo.User.GetById(userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errors.New("error: User not found"), codes.NotFound)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if err := revokeUserTokens(s.repo, s.cache, s.cfg, userId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset mails a single-use reset link to the owner of the
+// email. It succeeds for unknown emails as well so that the endpoint cannot be
+// used to discover registered accounts.
+func (s *authService) RequestPasswordReset(request models.PasswordResetRequest) error {
+	user, err := s.repo.User.GetByEmail(request.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
 		}
+		return serviceError(err, codes.Internal)
+	}
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(s.cfg.PasswordResetExpirationMinutes) * time.Minute)
+
+	if _, err = s.repo.PasswordReset.Create(models.CreatePasswordResetToken{
+		UserId:    user.Id,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	go func() {
+		if err := s.mailer.Send(mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your Tender Bridge password",
+			Body: fmt.Sprintf("Use the link below to choose a new password. It expires in %d minutes.\n\n%s/reset-password?token=%s",
+				s.cfg.PasswordResetExpirationMinutes,
+				s.cfg.AppBaseURL,
+				token,
+			),
+		}); err != nil {
+			s.logger.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *authService) ResetPassword(request models.PasswordResetConfirm) error {
+	token, err := s.repo.PasswordReset.GetByHash(helper.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errInvalidResetToken, codes.InvalidArgument)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if token.UsedAt != nil || token.ExpiresAt.Before(time.Now().UTC()) {
+		return serviceError(errInvalidResetToken, codes.InvalidArgument)
+	}
+
+	user, err := s.repo.User.GetById(token.UserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errInvalidResetToken, codes.InvalidArgument)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if err = s.passwords.Validate("password", request.Password, user.Username, user.Email); err != nil {
+		return serviceError(err, codes.InvalidArgument)
+	}
+
+	hash, err := helper.GenerateHash(request.Password)
+	if err != nil {
+		return serviceError(err, codes.InvalidArgument)
+	}
+
+	used, err := s.repo.PasswordReset.MarkUsed(token.Id)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
 
-		return []byte(config.GetConfig().JWTSecret), nil
-	})
+	if !used {
+		return serviceError(errInvalidResetToken, codes.InvalidArgument)
+	}
+
+	if err = s.repo.User.UpdatePassword(token.UserId, hash); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	// sessions opened with the old password must not survive the reset
+	return s.RevokeUserTokens(token.UserId)
+}
+
+func (s *authService) revokeReusedFamily(token models.RefreshToken) error {
+	s.logger.Warnf("refresh token reuse detected for user %s, revoking family %s", token.UserId, token.FamilyId)
+
+	if _, err := revokeSession(s.repo, s.cache, s.cfg, token.UserId, token.FamilyId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return serviceError(errRefreshTokenReused, codes.Unauthenticated)
+}
+
+// PublicKeys returns the keys other services can verify our tokens with
+func (s *authService) PublicKeys() jwks.JSONWebKeySet {
+	return s.keys.PublicKeys()
+}
+
+func (s *authService) ParseToken(token string) (*jwtCustomClaim, error) {
+	jwtToken, err := jwt.ParseWithClaims(token, &jwtCustomClaim{}, s.keys.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -98,28 +403,150 @@ func (s *authService) ParseToken(token string) (*jwtCustomClaim, error) {
 	return claims, nil
 }
 
-func (s *authService) Login(request models.Login) (*models.Token, *models.Token, error) {
+func (s *authService) Login(request models.Login, client models.Client) (*models.Token, *models.Token, error) {
+	if err := s.throttle.Check(request.Username, client.IP); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.repo.User.GetByUsername(request.Username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.throttle.Fail(request.Username, client.IP, nil)
 			return nil, nil, serviceError(errors.New("User not found"), codes.NotFound)
 		}
 		return nil, nil, serviceError(err, codes.Internal)
 	}
 
-	hashPassword, err := helper.GenerateHash(request.Password)
+	match, rehash, err := helper.ComparePassword(user.Password, request.Password)
 	if err != nil {
 		return nil, nil, serviceError(err, codes.Internal)
 	}
 
-	if user.Password != hashPassword {
+	if !match {
+		s.throttle.Fail(request.Username, client.IP, &user.Id)
 		return nil, nil, serviceError(errors.New("error: Invalid username or password"), codes.Unauthenticated)
 	}
 
-	return s.GenerateTokens(user)
+	if err = s.throttle.Reset(user.Username); err != nil {
+		s.logger.Error(err)
+	}
+
+	if err = s.throttle.CheckMFA(user.Id); err != nil {
+		return nil, nil, err
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, nil, serviceError(errAccountSuspended, codes.PermissionDenied)
+	}
+
+	if rehash {
+		s.upgradePasswordHash(user, request.Password)
+	}
+
+	mfa, err := s.repo.MFA.GetByUserId(user.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	// with two-factor authentication enabled the password only buys a short
+	// lived challenge token, which VerifyMFA exchanges for the real tokens
+	if err == nil && mfa.EnabledAt != nil {
+		challenge, err := s.CreateToken(user, config.TokenTypeMFA, time.Now().Add(mfaChallengeTTL))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return challenge, nil, nil
+	}
+
+	return s.GenerateTokens(user, client)
+}
+
+func (s *authService) VerifyMFA(request models.LoginMFA, client models.Client) (*models.Token, *models.Token, error) {
+	claims, err := s.ParseToken(request.MFAToken)
+	if err != nil || claims.Type != config.TokenTypeMFA {
+		return nil, nil, serviceError(errors.New("error: Invalid MFA token"), codes.Unauthenticated)
+	}
+
+	challengeKey := tokenDenylistKey(claims.Id)
+	challengeTTL := time.Until(time.Unix(claims.ExpiresAt, 0))
+
+	used, err := s.cache.Exists(challengeKey)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if used {
+		return nil, nil, serviceError(errors.New("error: MFA token has already been used"), codes.Unauthenticated)
+	}
+
+	attempts, err := s.cache.Increment("mfa_attempts:"+claims.Id, challengeTTL)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if attempts > mfaMaxAttempts {
+		if err = s.cache.Set(challengeKey, true, challengeTTL); err != nil {
+			s.logger.Error(err)
+		}
+		return nil, nil, serviceError(errors.New("error: Too many attempts, please log in again"), codes.Unauthenticated)
+	}
+
+	if err = s.throttle.CheckMFA(claims.UserId); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.repo.User.GetById(claims.UserId)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, nil, serviceError(errAccountSuspended, codes.PermissionDenied)
+	}
+
+	mfa, err := s.repo.MFA.GetByUserId(user.Id)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	ok, err := verifyMFACode(s.repo, s.cache, mfa, request.Code)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if !ok {
+		s.throttle.FailMFA(user.Id, client.IP)
+		return nil, nil, serviceError(errInvalidMFACode, codes.Unauthenticated)
+	}
+
+	if err = s.cache.Set(challengeKey, true, challengeTTL); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if err = s.throttle.ResetMFA(user.Id); err != nil {
+		s.logger.Error(err)
+	}
+
+	return s.GenerateTokens(user, client)
+}
+
+// upgradePasswordHash replaces a legacy or outdated hash after a successful
+// login. Failures are only logged, the old hash keeps working until the next
+// attempt.
+func (s *authService) upgradePasswordHash(user models.User, password string) {
+	hash, err := helper.GenerateHash(password)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+
+	if err = s.repo.User.UpdatePassword(user.Id, hash); err != nil {
+		s.logger.Error(err)
+	}
 }
 
-func (s *authService) Register(request models.Register) (*models.Token, *models.Token, error) {
+func (s *authService) Register(request models.Register, client models.Client) (*models.Token, *models.Token, error) {
 	// Check if the email already exists
 	_, err := s.repo.User.GetByEmail(request.Email) // Ensure GetByEmail belongs to s.repo.User
 	if err == nil {
@@ -136,6 +563,10 @@ func (s *authService) Register(request models.Register) (*models.Token, *models.
 		return nil, nil, serviceError(err, codes.Internal)
 	}
 
+	if err = s.passwords.Validate("password", request.Password, request.Username, request.Email); err != nil {
+		return nil, nil, serviceError(err, codes.InvalidArgument)
+	}
+
 	// Hash the password
 	request.Password, err = helper.GenerateHash(request.Password)
 	if err != nil {
@@ -153,12 +584,98 @@ func (s *authService) Register(request models.Register) (*models.Token, *models.
 		return nil, nil, serviceError(err, codes.Internal)
 	}
 
-	// Generate tokens for the new user
-	return s.GenerateTokens(models.User{
+	if err = createPersonalOrganization(s.repo, userId, request.Username, request.Role); err != nil {
+		return nil, nil, err
+	}
+
+	user := models.User{
 		Id:       userId,
 		Role:     request.Role,
 		Username: request.Username,
 		Email:    request.Email,
 		Password: request.Password,
-	})
+	}
+
+	if err = s.sendVerificationEmail(user); err != nil {
+		return nil, nil, err
+	}
+
+	// Generate tokens for the new user
+	return s.GenerateTokens(user, client)
+}
+
+func (s *authService) ResendVerificationEmail(userId uuid.UUID) error {
+	user, err := s.repo.User.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errors.New("error: User not found"), codes.NotFound)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return serviceError(errors.New("error: Email is already verified"), codes.InvalidArgument)
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *authService) VerifyEmail(request models.VerifyEmail) error {
+	token, err := s.repo.EmailVerification.GetByHash(helper.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errInvalidVerifyToken, codes.InvalidArgument)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if token.UsedAt != nil || token.ExpiresAt.Before(time.Now().UTC()) {
+		return serviceError(errInvalidVerifyToken, codes.InvalidArgument)
+	}
+
+	used, err := s.repo.EmailVerification.MarkUsed(token.Id)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !used {
+		return serviceError(errInvalidVerifyToken, codes.InvalidArgument)
+	}
+
+	if err = s.repo.User.MarkEmailVerified(token.UserId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *authService) sendVerificationEmail(user models.User) error {
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if _, err = s.repo.EmailVerification.Create(models.CreateEmailVerificationToken{
+		UserId:    user.Id,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(time.Duration(s.cfg.EmailVerificationExpirationHours) * time.Hour),
+	}); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	go func() {
+		if err := s.mailer.Send(mailer.Message{
+			To:      user.Email,
+			Subject: "Confirm your Tender Bridge email",
+			Body: fmt.Sprintf("Hi %s, confirm your email address by opening the link below.\n\n%s/verify-email?token=%s",
+				user.Username,
+				s.cfg.AppBaseURL,
+				token,
+			),
+		}); err != nil {
+			s.logger.Error(err)
+		}
+	}()
+
+	return nil
 }
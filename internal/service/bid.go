@@ -6,9 +6,12 @@ import (
 	"tender-bridge/config"
 	"tender-bridge/internal/cache"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
 	"tender-bridge/internal/repository"
 	"tender-bridge/internal/ws"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/sealer"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -22,22 +25,34 @@ var (
 type bidService struct {
 	repo   *repository.Repository
 	cache  *cache.RedisCache
+	sealer *sealer.Sealer
 	logger *logger.Logger
 }
 
-func NewBidService(repo *repository.Repository, cache *cache.RedisCache, logger *logger.Logger) *bidService {
+func NewBidService(repo *repository.Repository, cache *cache.RedisCache, sealer *sealer.Sealer, logger *logger.Logger) *bidService {
 	return &bidService{
 		repo:   repo,
 		cache:  cache,
+		sealer: sealer,
 		logger: logger,
 	}
 }
 
-func (s *bidService) SubmitBid(request models.CreateBid) (uuid.UUID, error) {
+func (s *bidService) SubmitBid(subject policy.Subject, request models.CreateBid) (uuid.UUID, error) {
 	if request.Price <= 0 || request.DeliveryTime <= 0 || request.Comment == "" {
 		return uuid.Nil, serviceError(errors.New("error: Invalid bid data"), codes.InvalidArgument)
 	}
 
+	if err := ensureEmailVerified(s.repo, request.ContractorId); err != nil {
+		return uuid.Nil, err
+	}
+
+	organizationId, err := resolveOrganization(s.repo, subject, policy.BidSubmit, request.OrganizationId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	request.OrganizationId = organizationId
+
 	tender, err := s.repo.Tender.GetById(request.TenderId)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return uuid.Nil, serviceError(err, codes.Internal)
@@ -45,12 +60,28 @@ func (s *bidService) SubmitBid(request models.CreateBid) (uuid.UUID, error) {
 		return uuid.Nil, serviceError(errors.New("Tender not found"), codes.NotFound)
 	}
 
-	if tender.Status != config.TenderStatusOpen {
+	if tender.Status != config.TenderStatusPublished {
 		return uuid.Nil, serviceError(errors.New("Tender is not open for bids"), codes.InvalidArgument)
 	}
 
+	// the scheduler closes expired tenders only periodically
+	if !tender.Deadline.After(time.Now()) {
+		return uuid.Nil, serviceError(errors.New("Tender deadline has passed"), codes.InvalidArgument)
+	}
+
 	request.Status = config.BidStatusPending
 
+	if tender.Sealed {
+		request.SealedData, err = sealBid(s.sealer, tender.Id, models.SealedBidData{
+			Price:        request.Price,
+			DeliveryTime: request.DeliveryTime,
+			Comment:      request.Comment,
+		})
+		if err != nil {
+			return uuid.Nil, serviceError(err, codes.Internal)
+		}
+	}
+
 	id, err := s.repo.Bid.Create(request)
 	if err != nil {
 		return uuid.Nil, serviceError(err, codes.Internal)
@@ -63,12 +94,38 @@ func (s *bidService) SubmitBid(request models.CreateBid) (uuid.UUID, error) {
 	return id, nil
 }
 
-func (s *bidService) GetBids(filter models.BidFilter) ([]models.Bid, int, error) {
-	bids, total, err := s.repo.Bid.GetList(filter)
+// GetBids lists a page of bids, newest first. Pages start at the filter's
+// cursor when it has one and at its offset otherwise.
+func (s *bidService) GetBids(filter models.BidFilter) ([]models.Bid, models.PageCursors, error) {
+	// results ranked by relevance have no sort key to page from
+	key := bidCursor
+	if filter.Search != "" {
+		key = nil
+	}
+
+	if filter.Cursor != nil {
+		if key == nil {
+			return nil, models.PageCursors{}, serviceError(errors.New("error: Search results cannot be paged with a cursor"), codes.InvalidArgument)
+		}
+
+		if filter.Cursor.Sort != "" || filter.Cursor.Order != "" || !validCursorValue(config.TenderSortCreatedAt, filter.Cursor.Value) {
+			return nil, models.PageCursors{}, serviceError(errInvalidCursor, codes.InvalidArgument)
+		}
+
+		filter.Offset = 0
+	}
+
+	// one more row tells whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+
+	bids, _, err := s.repo.Bid.GetList(filter)
 	if err != nil {
-		return nil, 0, serviceError(err, codes.Internal)
+		return nil, models.PageCursors{}, serviceError(err, codes.Internal)
 	}
 
+	bids, cursors := paginate(bids, limit, filter.Offset, filter.Cursor, key)
+
 	tenderIds := make([]uuid.UUID, len(bids))
 	for i := range bids {
 		tenderIds[i] = bids[i].TenderId
@@ -76,7 +133,7 @@ func (s *bidService) GetBids(filter models.BidFilter) ([]models.Bid, int, error)
 
 	tenders, err := s.repo.Tender.GetByIds(tenderIds)
 	if err != nil {
-		return nil, 0, serviceError(err, codes.Internal)
+		return nil, models.PageCursors{}, serviceError(err, codes.Internal)
 	}
 
 	tendersMap := make(map[uuid.UUID]models.Tender, len(tenders))
@@ -88,7 +145,84 @@ func (s *bidService) GetBids(filter models.BidFilter) ([]models.Bid, int, error)
 		bids[i].Tender = tendersMap[bids[i].TenderId]
 	}
 
-	return bids, total, nil
+	return bids, cursors, nil
+}
+
+func bidCursor(bid models.Bid) models.Cursor {
+	return models.Cursor{
+		Value: bid.CreatedAt.Format(time.RFC3339Nano),
+		Id:    bid.Id,
+	}
+}
+
+// GetMemberBids lists the bids of every organization the subject belongs to
+func (s *bidService) GetMemberBids(subject policy.Subject, filter models.BidFilter) ([]models.Bid, models.PageCursors, error) {
+	organizations, err := s.repo.Organization.GetListByUser(subject.Id)
+	if err != nil {
+		return nil, models.PageCursors{}, serviceError(err, codes.Internal)
+	}
+
+	filter.OrganizationIds = make([]uuid.UUID, len(organizations))
+	for i := range organizations {
+		filter.OrganizationIds[i] = organizations[i].Id
+	}
+
+	bids, cursors, err := s.GetBids(filter)
+	if err != nil {
+		return nil, models.PageCursors{}, err
+	}
+
+	// members may see what their organization offered on sealed tenders
+	for i := range bids {
+		if !bids[i].Sealed {
+			continue
+		}
+
+		data, err := unsealBid(s.sealer, bids[i])
+		if err != nil {
+			return nil, models.PageCursors{}, serviceError(err, codes.Internal)
+		}
+
+		bids[i].Price = data.Price
+		bids[i].DeliveryTime = data.DeliveryTime
+		bids[i].Comment = data.Comment
+	}
+
+	return bids, cursors, nil
+}
+
+// GetTenderBids lists the bids of a tender the subject is allowed to review.
+// While the bids of a sealed tender are hidden only their count is returned.
+func (s *bidService) GetTenderBids(subject policy.Subject, filter models.BidFilter) ([]models.Bid, models.PageCursors, *models.SealedBids, error) {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderListBids, filter.TenderId)
+	if err != nil {
+		return nil, models.PageCursors{}, nil, err
+	}
+
+	if bidsSealed(tender) {
+		_, total, err := s.repo.Bid.GetList(models.BidFilter{
+			TenderId: tender.Id,
+		})
+		if err != nil {
+			return nil, models.PageCursors{}, nil, serviceError(err, codes.Internal)
+		}
+
+		return nil, models.PageCursors{}, &models.SealedBids{
+			Sealed: true,
+			Count:  total,
+		}, nil
+	}
+
+	if err = openSealedBids(s.repo, s.sealer, tender, &subject.Id); err != nil {
+		return nil, models.PageCursors{}, nil, err
+	}
+
+	bids, cursors, err := s.GetBids(filter)
+	if err != nil {
+		return nil, models.PageCursors{}, nil, err
+	}
+
+	return bids, cursors, nil, nil
 }
 
 func (s *bidService) GetBid(id uuid.UUID) (models.Bid, error) {
@@ -108,71 +242,97 @@ func (s *bidService) UpdateBid(request models.UpdateBid) error {
 	return nil
 }
 
-func (s *bidService) DeleteContractorBid(contractorId, bidId uuid.UUID) error {
-	bid, err := s.repo.Bid.GetById(bidId)
+func (s *bidService) DeleteContractorBid(subject policy.Subject, bidId uuid.UUID) error {
+	if _, err := getAuthorizedBid(s.repo, subject, policy.BidDelete, bidId); err != nil {
+		return err
+	}
+
+	if err := s.repo.Bid.Delete(bidId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// AcknowledgeAmendment confirms that the bidder has seen every amendment made
+// to the tender so far
+func (s *bidService) AcknowledgeAmendment(subject policy.Subject, bidId uuid.UUID) error {
+	bid, err := getAuthorizedBid(s.repo, subject, policy.BidAcknowledge, bidId)
 	if err != nil {
-		return serviceError(errBidNotFound, codes.NotFound)
+		return err
 	}
 
-	if bid.ContractorId != contractorId {
-		return serviceError(errBidNotFound, codes.NotFound)
+	if bid.Status != config.BidStatusPending {
+		return serviceError(errors.New("error: The bid is not pending"), codes.InvalidArgument)
 	}
 
-	if err := s.repo.Bid.Delete(bidId); err != nil {
+	tender, err := s.repo.Tender.GetById(bid.TenderId)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if err = s.repo.Bid.Acknowledge(bid.Id, tender.Revision); err != nil {
 		return serviceError(err, codes.Internal)
 	}
 
 	return nil
 }
 
-func (s *bidService) AwardBid(clientId, tenderId, bidId uuid.UUID) error {
-	tender, err := s.repo.Tender.GetById(tenderId)
+func (s *bidService) AwardBid(subject policy.Subject, tenderId, bidId uuid.UUID) error {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderAward, tenderId)
 	if err != nil {
-		return serviceError(errTenderNotFound, codes.NotFound)
+		return err
 	}
 
-	if tender.ClientId != clientId {
-		return serviceError(errTenderNotFound, codes.NotFound)
+	if tender.Status != config.TenderStatusUnderEvaluation {
+		return serviceError(errors.New("the tender is not under evaluation"), codes.InvalidArgument)
 	}
 
-	if tender.Status != config.TenderStatusOpen {
-		return serviceError(errors.New("the tender is not open"), codes.InvalidArgument)
+	if err = openSealedBids(s.repo, s.sealer, tender, &subject.Id); err != nil {
+		return err
 	}
 
 	bid, err := s.repo.Bid.GetById(bidId)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errBidNotFound, codes.NotFound)
+		}
 		return serviceError(err, codes.Internal)
 	}
 
+	// only bids placed on this tender can win it
+	if bid.TenderId != tenderId {
+		return serviceError(errBidNotFound, codes.NotFound)
+	}
+
 	if bid.Status != config.BidStatusPending {
 		return serviceError(errors.New("the bid is not pending"), codes.InvalidArgument)
 	}
 
-	if err = s.repo.Tender.Update(models.UpdateTender{
-		Id:          tenderId,
-		ClientId:    tender.ClientId,
-		Title:       tender.Title,
-		Description: tender.Description,
-		Deadline:    tender.Deadline,
-		Budget:      tender.Budget,
-		File:        tender.File,
-		Status:      config.TenderStatusAwarded,
-	}); err != nil {
-		return serviceError(err, codes.Internal)
+	if bid.AcknowledgedRevision < tender.MaterialRevision {
+		return serviceError(errors.New("the bidder has not acknowledged the latest amendment of the tender"), codes.InvalidArgument)
 	}
 
-	if err = s.repo.Bid.Update(models.UpdateBid{
-		Id:           bidId,
-		ContractorId: bid.ContractorId,
-		TenderId:     tenderId,
-		Price:        bid.Price,
-		DeliveryTime: bid.DeliveryTime,
-		Comment:      bid.Comment,
-		Status:       config.BidStatusAwarded,
-	}); err != nil {
+	changed, err := s.repo.Tender.Award(models.TenderTransition{
+		TenderId:  tenderId,
+		From:      tender.Status,
+		To:        config.TenderStatusAwarded,
+		ChangedBy: &subject.Id,
+	}, bidId)
+	if err != nil {
 		return serviceError(err, codes.Internal)
 	}
 
+	if !changed {
+		return serviceError(errTenderStatusChanged, codes.Aborted)
+	}
+
+	go func() {
+		if err := s.cache.DeletePattern("tender_list*"); err != nil {
+			s.logger.Error(err)
+		}
+	}()
+
 	go func() {
 		ws.BroadcastNotification(bid.ContractorId.String(), "Your bid awarded")
 	}()
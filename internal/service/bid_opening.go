@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/sealer"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// sealBid encrypts the contents of a bid on a sealed tender. The tender id is
+// bound to the ciphertext so it cannot be moved to another tender.
+func sealBid(bids *sealer.Sealer, tenderId uuid.UUID, data models.SealedBidData) (string, error) {
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return bids.Seal(plaintext, tenderId[:])
+}
+
+func unsealBid(bids *sealer.Sealer, bid models.Bid) (models.SealedBidData, error) {
+	plaintext, err := bids.Open(bid.SealedData, bid.TenderId[:])
+	if err != nil {
+		return models.SealedBidData{}, fmt.Errorf("bid %s: %w", bid.Id, err)
+	}
+
+	var data models.SealedBidData
+	if err = json.Unmarshal(plaintext, &data); err != nil {
+		return models.SealedBidData{}, fmt.Errorf("bid %s: %w", bid.Id, err)
+	}
+
+	return data, nil
+}
+
+// bidsSealed reports whether the bid contents of the tender are still hidden.
+// Bids of a cancelled tender are never opened.
+func bidsSealed(tender models.Tender) bool {
+	if !tender.Sealed || tender.BidsOpenedAt != nil {
+		return false
+	}
+
+	switch tender.Status {
+	case config.TenderStatusDraft, config.TenderStatusPublished, config.TenderStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// openSealedBids decrypts the bids of a sealed tender once bidding is over and
+// records the opening in the audit log. openedBy is nil when the scheduler
+// closed the tender. Calling it for bids that are already open does nothing.
+func openSealedBids(repo *repository.Repository, bids *sealer.Sealer, tender models.Tender, openedBy *uuid.UUID) error {
+	if !tender.Sealed || tender.BidsOpenedAt != nil || bidsSealed(tender) {
+		return nil
+	}
+
+	sealed, err := repo.Bid.GetSealed(tender.Id)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	unsealed := make([]models.UnsealedBid, len(sealed))
+	for i := range sealed {
+		data, err := unsealBid(bids, sealed[i])
+		if err != nil {
+			return serviceError(err, codes.Internal)
+		}
+
+		unsealed[i] = models.UnsealedBid{
+			Id:            sealed[i].Id,
+			SealedBidData: data,
+		}
+	}
+
+	opened, err := repo.Bid.Unseal(tender.Id, unsealed)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !opened {
+		return nil
+	}
+
+	if _, err = repo.AuditLog.Create(models.CreateAuditLog{
+		Action:  config.AuditActionBidsOpened,
+		ActorId: openedBy,
+		Details: fmt.Sprintf("tender %s: %d sealed bids opened", tender.Id, len(unsealed)),
+	}); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/logger"
+	"unicode"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	maxTenderTags = 10
+	maxTagLength  = 32
+)
+
+var (
+	errCategoryNotFound = errors.New("error: Category not found")
+
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+type categoryService struct {
+	repo   *repository.Repository
+	logger *logger.Logger
+}
+
+func NewCategoryService(repo *repository.Repository, logger *logger.Logger) *categoryService {
+	return &categoryService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *categoryService) CreateCategory(request models.CreateCategory) (uuid.UUID, error) {
+	request.Name = strings.TrimSpace(request.Name)
+
+	if request.Slug == "" {
+		request.Slug = slugify(request.Name)
+	}
+
+	if !slugPattern.MatchString(request.Slug) {
+		return uuid.Nil, serviceError(errors.New("error: Slug may only contain lowercase letters, digits and dashes"), codes.InvalidArgument)
+	}
+
+	if request.ParentId != nil {
+		if _, err := getCategory(s.repo, *request.ParentId); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	id, err := s.repo.Category.Create(request)
+	if err != nil {
+		return uuid.Nil, serviceError(err, codes.Internal)
+	}
+
+	return id, nil
+}
+
+// GetCategories returns the category tree
+func (s *categoryService) GetCategories() ([]models.Category, error) {
+	categories, err := s.repo.Category.GetAll()
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+// DeleteCategory removes a category without subcategories. Its tenders are
+// left without a category.
+func (s *categoryService) DeleteCategory(id uuid.UUID) error {
+	categories, err := s.repo.Category.GetAll()
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	for _, category := range categories {
+		if category.ParentId != nil && *category.ParentId == id {
+			return serviceError(errors.New("error: Delete the subcategories first"), codes.InvalidArgument)
+		}
+	}
+
+	if err = s.repo.Category.Delete(id); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *categoryService) GetSubscriptions(userId uuid.UUID) ([]models.Category, error) {
+	categories, err := s.repo.Category.GetSubscriptions(userId)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return categories, nil
+}
+
+// Subscribe notifies the user of tenders published in the category or any of
+// its subcategories
+func (s *categoryService) Subscribe(userId, categoryId uuid.UUID) error {
+	if _, err := getCategory(s.repo, categoryId); err != nil {
+		return err
+	}
+
+	if err := s.repo.Category.Subscribe(userId, categoryId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *categoryService) Unsubscribe(userId, categoryId uuid.UUID) error {
+	if err := s.repo.Category.Unsubscribe(userId, categoryId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func getCategory(repo *repository.Repository, id uuid.UUID) (models.Category, error) {
+	category, err := repo.Category.GetById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Category{}, serviceError(errCategoryNotFound, codes.NotFound)
+		}
+		return models.Category{}, serviceError(err, codes.Internal)
+	}
+
+	return category, nil
+}
+
+func buildCategoryTree(categories []models.Category) []models.Category {
+	children := make(map[uuid.UUID][]models.Category, len(categories))
+	roots := []models.Category{}
+
+	for _, category := range categories {
+		if category.ParentId == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentId] = append(children[*category.ParentId], category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].Id])
+		}
+		return nodes
+	}
+
+	return attach(roots)
+}
+
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// normalizeTags lowercases and trims the tags and drops empty and repeated
+// ones
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		if len(tag) > maxTagLength {
+			return nil, serviceError(errors.New("error: Tags can be at most 32 characters long"), codes.InvalidArgument)
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTenderTags {
+		return nil, serviceError(errors.New("error: A tender can have at most 10 tags"), codes.InvalidArgument)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
+	"tender-bridge/internal/repository"
+	"tender-bridge/internal/ws"
+	"tender-bridge/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+var errClarificationNotFound = errors.New("error: Clarification not found")
+
+type clarificationService struct {
+	repo   *repository.Repository
+	logger *logger.Logger
+	cfg    *config.Config
+}
+
+func NewClarificationService(repo *repository.Repository, logger *logger.Logger, cfg *config.Config) *clarificationService {
+	return &clarificationService{
+		repo:   repo,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// AskQuestion posts a question on a published tender on behalf of one of
+// the subject's organizations. Questions close a configurable time before
+// the deadline so the client has time to answer.
+func (s *clarificationService) AskQuestion(subject policy.Subject, request models.CreateClarification) (uuid.UUID, error) {
+	if err := ensureEmailVerified(s.repo, subject.Id); err != nil {
+		return uuid.Nil, err
+	}
+
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderRead, request.TenderId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if tender.Status != config.TenderStatusPublished {
+		return uuid.Nil, serviceError(errors.New("error: Tender is not open for questions"), codes.InvalidArgument)
+	}
+
+	cutoff := tender.Deadline.Add(-time.Duration(s.cfg.ClarificationCutoffHours) * time.Hour)
+	if !time.Now().Before(cutoff) {
+		return uuid.Nil, serviceError(fmt.Errorf("error: Questions close %d hours before the deadline", s.cfg.ClarificationCutoffHours), codes.InvalidArgument)
+	}
+
+	organizationId, err := resolveOrganization(s.repo, subject, policy.ClarificationAsk, request.OrganizationId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	request.OrganizationId = organizationId
+	request.AskedBy = subject.Id
+
+	id, err := s.repo.Clarification.Create(request)
+	if err != nil {
+		return uuid.Nil, serviceError(err, codes.Internal)
+	}
+
+	go func() {
+		ws.BroadcastNotification(tender.ClientId.String(), fmt.Sprintf("New question on tender %q", tender.Title))
+	}()
+
+	return id, nil
+}
+
+// GetClarifications lists the answered questions of the tender and the
+// subject's own open ones. The tender's owners see every question and who
+// asked it; everyone else gets the answers without the asker.
+func (s *clarificationService) GetClarifications(subject policy.Subject, tenderId uuid.UUID) ([]models.Clarification, error) {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderRead, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err = withMembership(s.repo, subject, tender.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.CanAccessTender(subject, policy.TenderListBids, tender) {
+		clarifications, err := s.repo.Clarification.GetList(models.ClarificationFilter{
+			TenderId: tenderId,
+			All:      true,
+		})
+		if err != nil {
+			return nil, serviceError(err, codes.Internal)
+		}
+
+		return clarifications, nil
+	}
+
+	organizations, err := s.repo.Organization.GetListByUser(subject.Id)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	organizationIds := make([]uuid.UUID, len(organizations))
+	for i := range organizations {
+		organizationIds[i] = organizations[i].Id
+	}
+
+	clarifications, err := s.repo.Clarification.GetList(models.ClarificationFilter{
+		TenderId:        tenderId,
+		OrganizationIds: organizationIds,
+	})
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	for i := range clarifications {
+		clarifications[i].OrganizationId = nil
+		clarifications[i].AskedBy = nil
+	}
+
+	return clarifications, nil
+}
+
+// AnswerQuestion publishes the client's answer to every bidder
+func (s *clarificationService) AnswerQuestion(subject policy.Subject, request models.AnswerClarification) error {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.ClarificationAnswer, request.TenderId)
+	if err != nil {
+		return err
+	}
+
+	if tender.Status != config.TenderStatusPublished {
+		return serviceError(errors.New("error: Tender is not open for questions"), codes.InvalidArgument)
+	}
+
+	clarification, err := s.repo.Clarification.GetById(request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errClarificationNotFound, codes.NotFound)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if clarification.TenderId != tender.Id {
+		return serviceError(errClarificationNotFound, codes.NotFound)
+	}
+
+	if clarification.Answer != nil {
+		return serviceError(errors.New("error: The question is already answered"), codes.InvalidArgument)
+	}
+
+	request.AnsweredBy = subject.Id
+
+	if err = s.repo.Clarification.Answer(request); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	bidderIds, err := s.repo.Bid.GetBidderIds(tender.Id)
+	if err != nil {
+		s.logger.Error(err)
+	}
+
+	if clarification.AskedBy != nil {
+		bidderIds = append(bidderIds, *clarification.AskedBy)
+	}
+
+	go func() {
+		notified := make(map[uuid.UUID]bool, len(bidderIds))
+		for _, id := range bidderIds {
+			if notified[id] {
+				continue
+			}
+			notified[id] = true
+
+			ws.BroadcastNotification(id.String(), fmt.Sprintf("New clarification on tender %q", tender.Title))
+		}
+	}()
+
+	return nil
+}
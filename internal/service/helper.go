@@ -2,11 +2,22 @@ package service
 
 import (
 	"crypto/md5"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -41,6 +52,8 @@ func serviceError(err error, code codes.Code) error {
 	return status.Error(codes.Unknown, errMsg)
 }
 
+// generateCacheKeyTender hashes the whole filter, so every filter and sort
+// option gets its own cache entry
 func generateCacheKeyTender(filter models.TenderFilter) string {
 	filterBytes, _ := json.Marshal(filter)
 
@@ -56,3 +69,236 @@ func generateCacheKeyBid(filter models.BidFilter) string {
 
 	return fmt.Sprintf("bid_list_%x", hash)
 }
+
+// paginate trims rows, fetched with one row more than limit, to the page
+// and returns the cursors of the pages around it. key makes the cursor of a
+// row, lists without one get no cursors.
+func paginate[T any](rows []T, limit, offset int, cursor *models.Cursor, key func(T) models.Cursor) ([]T, models.PageCursors) {
+	var cursors models.PageCursors
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	if key == nil || len(rows) == 0 {
+		return rows, cursors
+	}
+
+	hasNext, hasPrev := hasMore, offset > 0 || cursor != nil
+	if cursor != nil && cursor.Backward {
+		// the rows came nearest first, the cached list must keep its order
+		rows = slices.Clone(rows)
+		slices.Reverse(rows)
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		cursors.Next = helper.EncodeCursor(key(rows[len(rows)-1]))
+	}
+
+	if hasPrev {
+		first := key(rows[0])
+		first.Backward = true
+		cursors.Prev = helper.EncodeCursor(first)
+	}
+
+	return rows, cursors
+}
+
+// validCursorValue reports whether a cursor value parses as the sort key.
+// Cursors come from clients, a tampered value would otherwise fail in the
+// database. Lists without a sort field are sorted by creation time.
+func validCursorValue(sort, value string) bool {
+	if sort == config.TenderSortBudget {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}
+
+	// Postgres has no year 0
+	t, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil && t.Year() >= 1
+}
+
+func tokenDenylistKey(tokenId string) string {
+	return "token_denylist:" + tokenId
+}
+
+func sessionRevokedKey(sessionId uuid.UUID) string {
+	return "session_revoked:" + sessionId.String()
+}
+
+func userTokensRevokedKey(userId uuid.UUID) string {
+	return "token_revoked_before:" + userId.String()
+}
+
+// revokeUserTokens invalidates every access token, refresh token and API key
+// issued to the user up to now. The access token marker only has to outlive
+// the longest access token lifetime.
+func revokeUserTokens(repo *repository.Repository, cache *cache.RedisCache, cfg *config.Config, userId uuid.UUID) error {
+	if err := revokeUserSessions(repo, cache, cfg, userId); err != nil {
+		return err
+	}
+
+	return repo.APIKey.RevokeByUser(userId)
+}
+
+// revokeUserSessions signs the user out everywhere but keeps their API keys
+func revokeUserSessions(repo *repository.Repository, cache *cache.RedisCache, cfg *config.Config, userId uuid.UUID) error {
+	ttl := time.Duration(cfg.JWTAccessExpirationHours) * time.Hour
+
+	if err := cache.Set(userTokensRevokedKey(userId), time.Now().Unix(), ttl); err != nil {
+		return err
+	}
+
+	if err := repo.RefreshToken.RevokeByUser(userId); err != nil {
+		return err
+	}
+
+	return repo.Session.RevokeByUser(userId)
+}
+
+// revokeSession ends one session of the user. Its refresh tokens are revoked
+// and the access tokens already issued for it are rejected until they expire.
+// It reports false when the session was not found or already ended.
+func revokeSession(repo *repository.Repository, cache *cache.RedisCache, cfg *config.Config, userId, sessionId uuid.UUID) (bool, error) {
+	revoked, err := repo.Session.Revoke(sessionId, userId)
+	if err != nil || !revoked {
+		return false, err
+	}
+
+	ttl := time.Duration(cfg.JWTAccessExpirationHours) * time.Hour
+
+	return true, cache.Set(sessionRevokedKey(sessionId), true, ttl)
+}
+
+var errEmailNotVerified = errors.New("error: Email address is not verified")
+
+// ensureEmailVerified blocks actions that require a confirmed email address
+func ensureEmailVerified(repo *repository.Repository, userId uuid.UUID) error {
+	user, err := repo.User.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errors.New("error: User not found"), codes.NotFound)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return serviceError(errEmailNotVerified, codes.PermissionDenied)
+	}
+
+	return nil
+}
+
+// getAuthorizedTender loads the tender and checks that the subject may
+// perform the action on it. Tenders the subject may not touch are reported
+// as missing so that their existence is not disclosed.
+func getAuthorizedTender(repo *repository.Repository, subject policy.Subject, action policy.Action, id uuid.UUID) (models.Tender, error) {
+	tender, err := repo.Tender.GetById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Tender{}, serviceError(errTenderNotFound, codes.NotFound)
+		}
+		return models.Tender{}, serviceError(err, codes.Internal)
+	}
+
+	subject, err = withMembership(repo, subject, tender.OrganizationId)
+	if err != nil {
+		return models.Tender{}, err
+	}
+
+	if !policy.CanAccessTender(subject, action, tender) {
+		return models.Tender{}, serviceError(errTenderNotFound, codes.NotFound)
+	}
+
+	return tender, nil
+}
+
+// getAuthorizedBid is the bid counterpart of getAuthorizedTender
+func getAuthorizedBid(repo *repository.Repository, subject policy.Subject, action policy.Action, id uuid.UUID) (models.Bid, error) {
+	bid, err := repo.Bid.GetById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Bid{}, serviceError(errBidNotFound, codes.NotFound)
+		}
+		return models.Bid{}, serviceError(err, codes.Internal)
+	}
+
+	subject, err = withMembership(repo, subject, bid.OrganizationId)
+	if err != nil {
+		return models.Bid{}, err
+	}
+
+	if !policy.CanAccessBid(subject, action, bid) {
+		return models.Bid{}, serviceError(errBidNotFound, codes.NotFound)
+	}
+
+	return bid, nil
+}
+
+// withMembership returns the subject with its role in the organization
+// loaded. Subjects that are not members are returned without one.
+func withMembership(repo *repository.Repository, subject policy.Subject, organizationId uuid.UUID) (policy.Subject, error) {
+	member, err := repo.Organization.GetMember(organizationId, subject.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return policy.Subject{}, serviceError(err, codes.Internal)
+	}
+
+	memberships := make(map[uuid.UUID]string, len(subject.Memberships)+1)
+	for id, role := range subject.Memberships {
+		memberships[id] = role
+	}
+
+	if err == nil {
+		memberships[organizationId] = member.Role
+	}
+
+	subject.Memberships = memberships
+
+	return subject, nil
+}
+
+// resolveOrganization picks the organization the subject acts for. When no
+// organization is given the first one allowing the action is used, which for
+// most users is the personal organization created at registration.
+func resolveOrganization(repo *repository.Repository, subject policy.Subject, action policy.Action, organizationId uuid.UUID) (uuid.UUID, error) {
+	organizations, err := repo.Organization.GetListByUser(subject.Id)
+	if err != nil {
+		return uuid.Nil, serviceError(err, codes.Internal)
+	}
+
+	subject.Memberships = make(map[uuid.UUID]string, len(organizations))
+	for _, organization := range organizations {
+		subject.Memberships[organization.Id] = organization.Role
+	}
+
+	if organizationId != uuid.Nil {
+		if !policy.CanAccessOrganization(subject, action, organizationId) {
+			return uuid.Nil, serviceError(errOrganizationNotFound, codes.NotFound)
+		}
+		return organizationId, nil
+	}
+
+	for _, organization := range organizations {
+		if policy.CanAccessOrganization(subject, action, organization.Id) {
+			return organization.Id, nil
+		}
+	}
+
+	return uuid.Nil, serviceError(errNoOrganization, codes.PermissionDenied)
+}
+
+// createPersonalOrganization gives a new client or contractor an
+// organization of their own, so they can work before joining a company.
+func createPersonalOrganization(repo *repository.Repository, userId uuid.UUID, username, role string) error {
+	if role == config.RoleAdmin {
+		return nil
+	}
+
+	if _, err := repo.Organization.Create(models.CreateOrganization{Name: username}, userId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/pkg/helper"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidCursorValue(t *testing.T) {
+	cases := []struct {
+		sort  string
+		value string
+		want  bool
+	}{
+		{config.TenderSortBudget, "1500", true},
+		{config.TenderSortBudget, "-1", true},
+		{config.TenderSortBudget, "15.5", false},
+		{config.TenderSortBudget, "1 OR 1=1", false},
+		{config.TenderSortBudget, "99999999999999999999", false},
+		{config.TenderSortBudget, "", false},
+		{config.TenderSortDeadline, "2026-10-17T10:00:00.123456Z", true},
+		{config.TenderSortDeadline, "2026-10-17", false},
+		{config.TenderSortDeadline, "1500", false},
+		{config.TenderSortCreatedAt, "2026-10-17T10:00:00Z", true},
+		{config.TenderSortCreatedAt, "0000-01-01T00:00:00Z", false},
+		{config.TenderSortCreatedAt, "yesterday", false},
+		{"", "2026-10-17T10:00:00+05:00", true},
+		{"", "", false},
+	}
+
+	for _, tc := range cases {
+		if got := validCursorValue(tc.sort, tc.value); got != tc.want {
+			t.Errorf("validCursorValue(%q, %q) = %v, want %v", tc.sort, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	ids := make([]int, 6)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	key := func(id int) models.Cursor {
+		return models.Cursor{Value: time.Unix(int64(id), 0).UTC().Format(time.RFC3339Nano), Id: uuid.UUID{byte(id)}}
+	}
+
+	decode := func(t *testing.T, encoded string) models.Cursor {
+		t.Helper()
+
+		var cursor models.Cursor
+		if err := helper.DecodeCursor(encoded, &cursor); err != nil {
+			t.Fatal(err)
+		}
+		return cursor
+	}
+
+	forward := &models.Cursor{}
+	backward := &models.Cursor{Backward: true}
+
+	cases := []struct {
+		name     string
+		rows     []int
+		offset   int
+		cursor   *models.Cursor
+		want     []int
+		nextFrom int
+		prevFrom int
+	}{
+		{name: "first page", rows: ids[:4], want: []int{1, 2, 3}, nextFrom: 3},
+		{name: "only page", rows: ids[:2], want: []int{1, 2}},
+		{name: "page by offset", rows: ids[3:], offset: 3, want: []int{4, 5, 6}, prevFrom: 4},
+		{name: "forward with more", rows: ids[1:5], cursor: forward, want: []int{2, 3, 4}, nextFrom: 4, prevFrom: 2},
+		{name: "forward at the end", rows: ids[4:], cursor: forward, want: []int{5, 6}, prevFrom: 5},
+		{name: "backward with more", rows: []int{5, 4, 3, 2}, cursor: backward, want: []int{3, 4, 5}, nextFrom: 5, prevFrom: 3},
+		{name: "backward at the start", rows: []int{2, 1}, cursor: backward, want: []int{1, 2}, nextFrom: 2},
+		{name: "empty", rows: nil, cursor: forward, want: nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := append([]int(nil), tc.rows...)
+
+			got, cursors := paginate(rows, 3, tc.offset, tc.cursor, key)
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("rows = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("rows = %v, want %v", got, tc.want)
+				}
+			}
+
+			if tc.nextFrom == 0 {
+				if cursors.Next != "" {
+					t.Error("unexpected next cursor")
+				}
+			} else if next := decode(t, cursors.Next); next.Id != key(tc.nextFrom).Id || next.Backward {
+				t.Errorf("next cursor %+v, want forward from %d", next, tc.nextFrom)
+			}
+
+			if tc.prevFrom == 0 {
+				if cursors.Prev != "" {
+					t.Error("unexpected previous cursor")
+				}
+			} else if prev := decode(t, cursors.Prev); prev.Id != key(tc.prevFrom).Id || !prev.Backward {
+				t.Errorf("previous cursor %+v, want backward from %d", prev, tc.prevFrom)
+			}
+
+			// backward pages are reversed on a copy, the cached rows keep
+			// their order
+			for i := range rows {
+				if rows[i] != tc.rows[i] {
+					t.Fatal("paginate changed the rows it was given")
+				}
+			}
+		})
+	}
+
+	if _, cursors := paginate(ids[:4], 3, 0, nil, nil); cursors != (models.PageCursors{}) {
+		t.Error("lists without a key got cursors")
+	}
+}
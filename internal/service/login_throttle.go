@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	loginScopeUser = "user"
+	loginScopeIP   = "ip"
+
+	// failed second factors are counted per account, since a new challenge
+	// is only a correct password away
+	loginScopeMFA = "mfa"
+
+	// failures below this count are free, each one after it doubles the
+	// wait before the next attempt, up to maxLoginDelay
+	loginDelayAfter = 2
+	maxLoginDelay   = 30 * time.Second
+)
+
+var errTooManyLoginAttempts = errors.New("error: Too many failed login attempts")
+
+// loginThrottle counts failed logins per username and per client IP in
+// Redis. Repeated failures slow further attempts down and, at the limit,
+// lock them out for LoginLockoutMinutes.
+type loginThrottle struct {
+	repo   *repository.Repository
+	cache  *cache.RedisCache
+	logger *logger.Logger
+	cfg    *config.Config
+}
+
+func newLoginThrottle(repo *repository.Repository, cache *cache.RedisCache, logger *logger.Logger, cfg *config.Config) *loginThrottle {
+	return &loginThrottle{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Check fails with ResourceExhausted while the username or the IP has to
+// wait before trying again
+func (t *loginThrottle) Check(username, ip string) error {
+	for _, key := range []string{loginLockKey(loginScopeUser, username), loginLockKey(loginScopeIP, ip)} {
+		wait, err := t.cache.TTL(key)
+		if err != nil {
+			return serviceError(err, codes.Internal)
+		}
+
+		if wait > 0 {
+			seconds := int((wait + time.Second - 1) / time.Second)
+			return serviceError(fmt.Errorf("%w, try again in %d seconds", errTooManyLoginAttempts, seconds), codes.ResourceExhausted)
+		}
+	}
+
+	return nil
+}
+
+// CheckMFA fails with ResourceExhausted while the account is locked for
+// failed second factors
+func (t *loginThrottle) CheckMFA(userId uuid.UUID) error {
+	wait, err := t.cache.TTL(loginLockKey(loginScopeMFA, userId.String()))
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if wait > 0 {
+		seconds := int((wait + time.Second - 1) / time.Second)
+		return serviceError(fmt.Errorf("%w, try again in %d seconds", errTooManyLoginAttempts, seconds), codes.ResourceExhausted)
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt. userId is nil when the username does not
+// belong to an account.
+func (t *loginThrottle) Fail(username, ip string, userId *uuid.UUID) {
+	t.fail(loginScopeUser, username, t.cfg.LoginMaxAttempts, userId, ip)
+	t.fail(loginScopeIP, ip, t.cfg.LoginMaxAttemptsPerIP, nil, ip)
+}
+
+// FailMFA records a wrong TOTP or recovery code
+func (t *loginThrottle) FailMFA(userId uuid.UUID, ip string) {
+	t.fail(loginScopeMFA, userId.String(), t.cfg.LoginMaxAttempts, &userId, ip)
+	t.fail(loginScopeIP, ip, t.cfg.LoginMaxAttemptsPerIP, nil, ip)
+}
+
+// ResetMFA forgets the failed second factors of an account. A correct
+// password does not, only a correct second factor or an admin unlock.
+func (t *loginThrottle) ResetMFA(userId uuid.UUID) error {
+	if err := t.cache.Delete(loginFailuresKey(loginScopeMFA, userId.String())); err != nil {
+		return err
+	}
+
+	return t.cache.Delete(loginLockKey(loginScopeMFA, userId.String()))
+}
+
+// Reset forgets the failures of a username after a successful login or an
+// admin unlock. The IP counter is kept, so that one known password does
+// not clear the way for guessing others from the same address.
+func (t *loginThrottle) Reset(username string) error {
+	if err := t.cache.Delete(loginFailuresKey(loginScopeUser, username)); err != nil {
+		return err
+	}
+
+	return t.cache.Delete(loginLockKey(loginScopeUser, username))
+}
+
+func (t *loginThrottle) fail(scope, value string, limit int, userId *uuid.UUID, ip string) {
+	if value == "" {
+		return
+	}
+
+	lockout := time.Duration(t.cfg.LoginLockoutMinutes) * time.Minute
+
+	count, err := t.cache.Increment(loginFailuresKey(scope, value), lockout)
+	if err != nil {
+		t.logger.Error(err)
+		return
+	}
+
+	if limit > 0 && count >= int64(limit) {
+		if err = t.cache.Set(loginLockKey(scope, value), count, lockout); err != nil {
+			t.logger.Error(err)
+			return
+		}
+
+		// the lock outlives the counter, so the next failure after it ends
+		// starts from zero instead of locking again immediately
+		if err = t.cache.Delete(loginFailuresKey(scope, value)); err != nil {
+			t.logger.Error(err)
+		}
+
+		if _, err = t.repo.AuditLog.Create(models.CreateAuditLog{
+			Action:    config.AuditActionLoginLocked,
+			UserId:    userId,
+			IPAddress: ip,
+			Details:   fmt.Sprintf("%s %s locked for %s after %d failed attempts", scope, value, lockout, count),
+		}); err != nil {
+			t.logger.Error(err)
+		}
+		return
+	}
+
+	if count > loginDelayAfter {
+		delay := min(time.Second<<(count-loginDelayAfter-1), maxLoginDelay)
+		if err = t.cache.Set(loginLockKey(scope, value), count, delay); err != nil {
+			t.logger.Error(err)
+		}
+	}
+}
+
+func loginFailuresKey(scope, value string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, value)
+}
+
+func loginLockKey(scope, value string) string {
+	return fmt.Sprintf("login_lock:%s:%s", scope, value)
+}
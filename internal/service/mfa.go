@@ -0,0 +1,186 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/totp"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	recoveryCodeCount = 10
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaMaxAttempts    = 5
+)
+
+var (
+	errMFANotEnrolled    = errors.New("error: Two-factor authentication is not set up")
+	errMFAAlreadyEnabled = errors.New("error: Two-factor authentication is already enabled")
+	errInvalidMFACode    = errors.New("error: Invalid authentication code")
+)
+
+type mfaService struct {
+	repo   *repository.Repository
+	cache  *cache.RedisCache
+	logger *logger.Logger
+	cfg    *config.Config
+}
+
+func NewMFAService(repo *repository.Repository, cache *cache.RedisCache, logger *logger.Logger, cfg *config.Config) *mfaService {
+	return &mfaService{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+func (s *mfaService) EnrollTOTP(userId uuid.UUID) (models.TOTPEnrollment, error) {
+	user, err := s.repo.User.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TOTPEnrollment{}, serviceError(errors.New("error: User not found"), codes.NotFound)
+		}
+		return models.TOTPEnrollment{}, serviceError(err, codes.Internal)
+	}
+
+	mfa, err := s.repo.MFA.GetByUserId(userId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.TOTPEnrollment{}, serviceError(err, codes.Internal)
+	} else if err == nil && mfa.EnabledAt != nil {
+		return models.TOTPEnrollment{}, serviceError(errMFAAlreadyEnabled, codes.InvalidArgument)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.TOTPEnrollment{}, serviceError(err, codes.Internal)
+	}
+
+	if err = s.repo.MFA.SaveSecret(userId, secret); err != nil {
+		return models.TOTPEnrollment{}, serviceError(err, codes.Internal)
+	}
+
+	return models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(secret, s.cfg.MFAIssuer, user.Email),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the
+// authenticator app is set up, and returns the one-time recovery codes.
+func (s *mfaService) ConfirmTOTP(userId uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.repo.MFA.GetByUserId(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, serviceError(errMFANotEnrolled, codes.InvalidArgument)
+		}
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	if mfa.EnabledAt != nil {
+		return nil, serviceError(errMFAAlreadyEnabled, codes.InvalidArgument)
+	}
+
+	ok, err := verifyTOTP(s.cache, mfa, code)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	if !ok {
+		return nil, serviceError(errInvalidMFACode, codes.InvalidArgument)
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, serviceError(err, codes.Internal)
+		}
+		hashes[i] = helper.HashToken(normalizeRecoveryCode(recoveryCodes[i]))
+	}
+
+	if err = s.repo.MFA.Enable(userId, hashes); err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return recoveryCodes, nil
+}
+
+func (s *mfaService) DisableTOTP(userId uuid.UUID, code string) error {
+	mfa, err := s.repo.MFA.GetByUserId(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errMFANotEnrolled, codes.InvalidArgument)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if mfa.EnabledAt == nil {
+		return serviceError(errMFANotEnrolled, codes.InvalidArgument)
+	}
+
+	ok, err := verifyMFACode(s.repo, s.cache, mfa, code)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !ok {
+		return serviceError(errInvalidMFACode, codes.InvalidArgument)
+	}
+
+	if err = s.repo.MFA.Delete(userId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// verifyMFACode accepts either a current TOTP code or an unused recovery code
+func verifyMFACode(repo *repository.Repository, cache *cache.RedisCache, mfa models.UserMFA, code string) (bool, error) {
+	ok, err := verifyTOTP(cache, mfa, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	return repo.MFA.UseRecoveryCode(mfa.UserId, helper.HashToken(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP validates the code and remembers the matched period, because a
+// code stays valid for the whole drift window and must not be replayed
+func verifyTOTP(cache *cache.RedisCache, mfa models.UserMFA, code string) (bool, error) {
+	counter, ok := totp.Validate(mfa.TotpSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	key := fmt.Sprintf("mfa_totp_used:%s:%d", mfa.UserId, counter)
+
+	return cache.SetIfNotExists(key, true, 3*totp.Period*time.Second)
+}
+
+func generateRecoveryCode() (string, error) {
+	code, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	code = strings.ToLower(code[:10])
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/oidc"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	errUnknownProvider   = errors.New("error: Unknown identity provider")
+	errInvalidOIDCState  = errors.New("error: Invalid or expired sign-in request")
+	errOIDCLoginFailed   = errors.New("error: Sign-in with the identity provider failed")
+	errOIDCEmailRequired = errors.New("error: Identity provider did not share an email address")
+	errOIDCEmailTaken    = errors.New("error: An account with this email already exists, sign in with your password")
+)
+
+type oidcService struct {
+	repo      *repository.Repository
+	cache     *cache.RedisCache
+	auth      Authorization
+	providers map[string]*oidc.Provider
+	logger    *logger.Logger
+	cfg       *config.Config
+}
+
+func NewOIDCService(repo *repository.Repository, cache *cache.RedisCache, auth Authorization, providers map[string]*oidc.Provider, logger *logger.Logger, cfg *config.Config) *oidcService {
+	return &oidcService{
+		repo:      repo,
+		cache:     cache,
+		auth:      auth,
+		providers: providers,
+		logger:    logger,
+		cfg:       cfg,
+	}
+}
+
+func (s *oidcService) GetProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoginURL starts an authorization code flow with PKCE. The state, nonce and
+// code verifier are kept in Redis until the provider redirects back.
+func (s *oidcService) LoginURL(provider string) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", serviceError(errUnknownProvider, codes.NotFound)
+	}
+
+	state, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", serviceError(err, codes.Internal)
+	}
+
+	nonce, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", serviceError(err, codes.Internal)
+	}
+
+	verifier, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", serviceError(err, codes.Internal)
+	}
+
+	url, err := p.AuthCodeURL(s.redirectURI(provider), state, nonce, verifier)
+	if err != nil {
+		s.logger.Error(err)
+		return "", serviceError(errOIDCLoginFailed, codes.Internal)
+	}
+
+	if err = s.cache.Set(oidcStateKey(state), models.OIDCState{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, oidcStateTTL); err != nil {
+		return "", serviceError(err, codes.Internal)
+	}
+
+	return url, nil
+}
+
+// Callback finishes the flow: the state is consumed, the code is exchanged
+// and the ID token verified before the user is signed in
+func (s *oidcService) Callback(provider, code, state string, client models.Client) (*models.Token, *models.Token, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, nil, serviceError(errUnknownProvider, codes.NotFound)
+	}
+
+	var pending models.OIDCState
+	if err := s.cache.Take(oidcStateKey(state), &pending); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, nil, serviceError(errInvalidOIDCState, codes.InvalidArgument)
+		}
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if pending.Provider != provider {
+		return nil, nil, serviceError(errInvalidOIDCState, codes.InvalidArgument)
+	}
+
+	rawIDToken, err := p.Exchange(code, s.redirectURI(provider), pending.CodeVerifier)
+	if err != nil {
+		s.logger.Error(err)
+		return nil, nil, serviceError(errOIDCLoginFailed, codes.Unauthenticated)
+	}
+
+	claims, err := p.VerifyIDToken(rawIDToken, pending.Nonce)
+	if err != nil {
+		s.logger.Error(err)
+		return nil, nil, serviceError(errOIDCLoginFailed, codes.Unauthenticated)
+	}
+
+	user, err := s.findOrProvisionUser(p, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, nil, serviceError(errAccountSuspended, codes.PermissionDenied)
+	}
+
+	return s.auth.GenerateTokens(user, client)
+}
+
+// findOrProvisionUser maps the provider account to a local user. Unknown
+// accounts get a new user, or are linked to an existing one with the same
+// email when the provider is trusted to have verified it.
+func (s *oidcService) findOrProvisionUser(p *oidc.Provider, claims *oidc.IDToken) (models.User, error) {
+	identity, err := s.repo.Identity.GetBySubject(p.Name, claims.Subject)
+	if err == nil {
+		user, err := s.repo.User.GetById(identity.UserId)
+		if err != nil {
+			return models.User{}, serviceError(err, codes.Internal)
+		}
+		return user, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	if claims.Email == "" {
+		return models.User{}, serviceError(errOIDCEmailRequired, codes.InvalidArgument)
+	}
+
+	user, err := s.repo.User.GetByEmail(claims.Email)
+	if err == nil {
+		if !p.LinkByEmail || !claims.EmailVerified {
+			return models.User{}, serviceError(errOIDCEmailTaken, codes.AlreadyExists)
+		}
+	} else if errors.Is(err, sql.ErrNoRows) {
+		if user, err = s.provisionUser(p, claims); err != nil {
+			return models.User{}, err
+		}
+	} else {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	if _, err = s.repo.Identity.Create(models.CreateUserIdentity{
+		UserId:   user.Id,
+		Provider: p.Name,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	return user, nil
+}
+
+func (s *oidcService) provisionUser(p *oidc.Provider, claims *oidc.IDToken) (models.User, error) {
+	role := p.DefaultRole
+	if role == "" {
+		role = config.RoleClient
+	}
+
+	if role != config.RoleClient && role != config.RoleContractor {
+		return models.User{}, serviceError(fmt.Errorf("invalid default role %q for provider %s", role, p.Name), codes.Internal)
+	}
+
+	username, err := s.uniqueUsername(claims)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// the account signs in through the provider, so the local password is
+	// random and unknown to anyone until a password reset sets one
+	secret, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	password, err := helper.GenerateHash(secret)
+	if err != nil {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	id, err := s.repo.User.Create(models.CreateUser{
+		Role:     role,
+		Username: username,
+		Email:    claims.Email,
+		Password: password,
+	})
+	if err != nil {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	if claims.EmailVerified {
+		if err = s.repo.User.MarkEmailVerified(id); err != nil {
+			return models.User{}, serviceError(err, codes.Internal)
+		}
+	}
+
+	if err = createPersonalOrganization(s.repo, id, username, role); err != nil {
+		return models.User{}, err
+	}
+
+	user, err := s.repo.User.GetById(id)
+	if err != nil {
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	return user, nil
+}
+
+// uniqueUsername derives a username from the provider profile, adding a
+// number when it is already taken
+func (s *oidcService) uniqueUsername(claims *oidc.IDToken) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	for i := 1; i <= 20; i++ {
+		username := base
+		if i > 1 {
+			username = fmt.Sprintf("%s%d", base, i)
+		}
+
+		_, err := s.repo.User.GetByUsername(username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		} else if err != nil {
+			return "", serviceError(err, codes.Internal)
+		}
+	}
+
+	suffix, err := helper.GenerateRandomToken(4)
+	if err != nil {
+		return "", serviceError(err, codes.Internal)
+	}
+
+	return base + "-" + suffix, nil
+}
+
+func (s *oidcService) redirectURI(provider string) string {
+	return fmt.Sprintf("%s/oidc/%s/callback", s.cfg.AppBaseURL, provider)
+}
+
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"database/sql"
+	"tender-bridge/config"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/oidc"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUsers keeps users in memory. Methods the OIDC flow does not use are
+// left to the embedded nil interface and panic when called.
+type fakeUsers struct {
+	repository.User
+	users map[uuid.UUID]models.User
+}
+
+func (f *fakeUsers) Create(request models.CreateUser) (uuid.UUID, error) {
+	id := uuid.New()
+	f.users[id] = models.User{
+		Id:       id,
+		Role:     request.Role,
+		Username: request.Username,
+		Email:    request.Email,
+		Password: request.Password,
+	}
+	return id, nil
+}
+
+func (f *fakeUsers) GetById(id uuid.UUID) (models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return models.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) GetByEmail(email string) (models.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (f *fakeUsers) GetByUsername(username string) (models.User, error) {
+	for _, user := range f.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (f *fakeUsers) MarkEmailVerified(id uuid.UUID) error {
+	user := f.users[id]
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	f.users[id] = user
+	return nil
+}
+
+type fakeIdentities struct {
+	repository.Identity
+	identities []models.CreateUserIdentity
+}
+
+func (f *fakeIdentities) Create(request models.CreateUserIdentity) (uuid.UUID, error) {
+	f.identities = append(f.identities, request)
+	return uuid.New(), nil
+}
+
+func (f *fakeIdentities) GetBySubject(provider, subject string) (models.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return models.UserIdentity{
+				UserId:   identity.UserId,
+				Provider: identity.Provider,
+				Subject:  identity.Subject,
+			}, nil
+		}
+	}
+	return models.UserIdentity{}, sql.ErrNoRows
+}
+
+type fakeOrganizations struct {
+	repository.Organization
+	owners []uuid.UUID
+}
+
+func (f *fakeOrganizations) Create(request models.CreateOrganization, ownerId uuid.UUID) (uuid.UUID, error) {
+	f.owners = append(f.owners, ownerId)
+	return uuid.New(), nil
+}
+
+func TestOIDCFindOrProvisionUser(t *testing.T) {
+	existing := models.User{
+		Id:       uuid.New(),
+		Role:     config.RoleContractor,
+		Username: "existing",
+		Email:    "user@example.com",
+	}
+
+	cases := []struct {
+		name        string
+		linkByEmail bool
+		claims      oidc.IDToken
+		linked      bool
+		code        codes.Code
+
+		// provisioned reports whether a new user is expected
+		provisioned bool
+	}{
+		{
+			name:        "unknown email provisions a user",
+			claims:      oidc.IDToken{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, PreferredUsername: "existing"},
+			provisioned: true,
+		},
+		{
+			name:        "unknown email provisions a user when linking is on",
+			linkByEmail: true,
+			claims:      oidc.IDToken{Subject: "sub-1", Email: "new@example.com"},
+			provisioned: true,
+		},
+		{
+			name:        "verified email links to the existing user",
+			linkByEmail: true,
+			claims:      oidc.IDToken{Subject: "sub-1", Email: existing.Email, EmailVerified: true},
+			linked:      true,
+		},
+		{
+			name:        "unverified email is not linked",
+			linkByEmail: true,
+			claims:      oidc.IDToken{Subject: "sub-1", Email: existing.Email},
+			code:        codes.AlreadyExists,
+		},
+		{
+			name:   "existing email is not linked without link by email",
+			claims: oidc.IDToken{Subject: "sub-1", Email: existing.Email, EmailVerified: true},
+			code:   codes.AlreadyExists,
+		},
+		{
+			name:   "missing email",
+			claims: oidc.IDToken{Subject: "sub-1"},
+			code:   codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := &fakeUsers{users: map[uuid.UUID]models.User{existing.Id: existing}}
+			identities := &fakeIdentities{}
+			organizations := &fakeOrganizations{}
+
+			s := &oidcService{repo: &repository.Repository{
+				User:         users,
+				Identity:     identities,
+				Organization: organizations,
+			}}
+
+			provider := oidc.NewProvider(oidc.Config{
+				Name:        "mock",
+				Issuer:      "https://idp.example.com",
+				ClientId:    "tender-bridge",
+				DefaultRole: config.RoleClient,
+				LinkByEmail: tc.linkByEmail,
+			})
+
+			user, err := s.findOrProvisionUser(provider, &tc.claims)
+			if tc.code != codes.OK {
+				if status.Code(err) != tc.code {
+					t.Fatalf("got error %v, want code %s", err, tc.code)
+				}
+				if len(identities.identities) != 0 || len(users.users) != 1 {
+					t.Fatal("a rejected login changed users or identities")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			switch {
+			case tc.linked:
+				if user.Id != existing.Id {
+					t.Fatal("the login was not linked to the existing user")
+				}
+				if len(users.users) != 1 || len(organizations.owners) != 0 {
+					t.Fatal("linking created a user or an organization")
+				}
+			case tc.provisioned:
+				if user.Id == existing.Id || len(users.users) != 2 {
+					t.Fatal("no user was provisioned")
+				}
+				if user.Role != config.RoleClient || user.Email != tc.claims.Email {
+					t.Errorf("provisioned user %+v", user)
+				}
+				if user.Username == existing.Username {
+					t.Error("the provisioned user took a username that is in use")
+				}
+				if (user.EmailVerifiedAt != nil) != tc.claims.EmailVerified {
+					t.Error("email verification does not follow the provider")
+				}
+				if len(organizations.owners) != 1 || organizations.owners[0] != user.Id {
+					t.Error("the provisioned user got no personal organization")
+				}
+			}
+
+			if len(identities.identities) != 1 || identities.identities[0].UserId != user.Id || identities.identities[0].Subject != tc.claims.Subject {
+				t.Fatalf("identity not recorded: %+v", identities.identities)
+			}
+
+			// the next login with the same subject finds the identity
+			again, err := s.findOrProvisionUser(provider, &tc.claims)
+			if err != nil {
+				t.Fatalf("second login: %v", err)
+			}
+			if again.Id != user.Id || len(identities.identities) != 1 {
+				t.Fatal("the second login did not reuse the identity")
+			}
+		})
+	}
+}
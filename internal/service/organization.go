@@ -0,0 +1,283 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"tender-bridge/config"
+	"tender-bridge/internal/mailer"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	errOrganizationNotFound = errors.New("error: Organization not found or access denied")
+	errNoOrganization       = errors.New("error: You are not allowed to act for any organization")
+	errMemberNotFound       = errors.New("error: Member not found")
+	errLastOwner            = errors.New("error: Organization must keep at least one owner")
+	errInvalidOrgRole       = errors.New("error: Invalid organization role")
+	errInvalidInvitation    = errors.New("error: Invalid or expired invitation")
+	errInvitationEmail      = errors.New("error: Invitation was sent to another email")
+)
+
+type organizationService struct {
+	repo   *repository.Repository
+	mailer mailer.Sender
+	logger *logger.Logger
+	cfg    *config.Config
+}
+
+func NewOrganizationService(repo *repository.Repository, mailer mailer.Sender, logger *logger.Logger, cfg *config.Config) *organizationService {
+	return &organizationService{
+		repo:   repo,
+		mailer: mailer,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+func (s *organizationService) CreateOrganization(userId uuid.UUID, request models.CreateOrganization) (uuid.UUID, error) {
+	id, err := s.repo.Organization.Create(request, userId)
+	if err != nil {
+		return uuid.Nil, serviceError(err, codes.Internal)
+	}
+
+	return id, nil
+}
+
+func (s *organizationService) GetOrganizations(userId uuid.UUID) ([]models.Organization, error) {
+	organizations, err := s.repo.Organization.GetListByUser(userId)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return organizations, nil
+}
+
+func (s *organizationService) GetOrganization(subject policy.Subject, id uuid.UUID) (models.Organization, error) {
+	subject, err := s.authorize(subject, policy.OrganizationRead, id)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	organization, err := s.repo.Organization.GetById(id)
+	if err != nil {
+		return models.Organization{}, serviceError(err, codes.Internal)
+	}
+	organization.Role = subject.Memberships[id]
+
+	return organization, nil
+}
+
+func (s *organizationService) GetMembers(subject policy.Subject, id uuid.UUID) ([]models.OrganizationMember, error) {
+	if _, err := s.authorize(subject, policy.OrganizationRead, id); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.Organization.GetMembers(id)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return members, nil
+}
+
+func (s *organizationService) UpdateMemberRole(subject policy.Subject, organizationId, userId uuid.UUID, role string) error {
+	if _, err := s.authorize(subject, policy.OrganizationManageMembers, organizationId); err != nil {
+		return err
+	}
+
+	if !policy.CanAssignOrganizationRole(config.OrgRoleOwner, role) {
+		return serviceError(errInvalidOrgRole, codes.InvalidArgument)
+	}
+
+	member, err := s.getMember(organizationId, userId)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == config.OrgRoleOwner && role != config.OrgRoleOwner {
+		if err = s.ensureAnotherOwner(organizationId); err != nil {
+			return err
+		}
+	}
+
+	if err = s.repo.Organization.UpdateMemberRole(organizationId, userId, role); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from the organization. Owners can remove
+// anyone, every other member can only leave on their own.
+func (s *organizationService) RemoveMember(subject policy.Subject, organizationId, userId uuid.UUID) error {
+	action := policy.OrganizationManageMembers
+	if userId == subject.Id {
+		action = policy.OrganizationRead
+	}
+
+	if _, err := s.authorize(subject, action, organizationId); err != nil {
+		return err
+	}
+
+	member, err := s.getMember(organizationId, userId)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == config.OrgRoleOwner {
+		if err = s.ensureAnotherOwner(organizationId); err != nil {
+			return err
+		}
+	}
+
+	if err = s.repo.Organization.DeleteMember(organizationId, userId); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// InviteMember emails a single-use invitation link. Managers can invite
+// managers and viewers, only owners can invite other owners.
+func (s *organizationService) InviteMember(subject policy.Subject, organizationId uuid.UUID, request models.InviteMember) error {
+	subject, err := s.authorize(subject, policy.OrganizationInvite, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if !policy.CanAssignOrganizationRole(config.OrgRoleOwner, request.Role) {
+		return serviceError(errInvalidOrgRole, codes.InvalidArgument)
+	}
+
+	if !policy.CanAssignOrganizationRole(subject.Memberships[organizationId], request.Role) {
+		return serviceError(errors.New("error: Only owners can invite owners"), codes.PermissionDenied)
+	}
+
+	organization, err := s.repo.Organization.GetById(organizationId)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if _, err = s.repo.Organization.CreateInvitation(models.CreateOrganizationInvitation{
+		OrganizationId: organizationId,
+		Email:          request.Email,
+		Role:           request.Role,
+		TokenHash:      helper.HashToken(token),
+		InvitedBy:      subject.Id,
+		ExpiresAt:      time.Now().UTC().Add(time.Duration(s.cfg.InvitationExpirationHours) * time.Hour),
+	}); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	go func() {
+		if err := s.mailer.Send(mailer.Message{
+			To:      request.Email,
+			Subject: fmt.Sprintf("You are invited to join %s on Tender Bridge", organization.Name),
+			Body: fmt.Sprintf("You have been invited to join %s as %s. Sign in with this email address and open the link below to accept.\n\n%s/organizations/invitations/accept?token=%s",
+				organization.Name,
+				request.Role,
+				s.cfg.AppBaseURL,
+				token,
+			),
+		}); err != nil {
+			s.logger.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+// AcceptInvitation adds the user to the inviting organization. The
+// invitation must have been sent to the user's verified email address.
+func (s *organizationService) AcceptInvitation(userId uuid.UUID, request models.AcceptInvitation) error {
+	invitation, err := s.repo.Organization.GetInvitationByHash(helper.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errInvalidInvitation, codes.InvalidArgument)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	if invitation.AcceptedAt != nil || time.Now().UTC().After(invitation.ExpiresAt) {
+		return serviceError(errInvalidInvitation, codes.InvalidArgument)
+	}
+
+	user, err := s.repo.User.GetById(userId)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return serviceError(errInvitationEmail, codes.PermissionDenied)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return serviceError(errEmailNotVerified, codes.PermissionDenied)
+	}
+
+	accepted, err := s.repo.Organization.AcceptInvitation(invitation, userId)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !accepted {
+		return serviceError(errInvalidInvitation, codes.InvalidArgument)
+	}
+
+	return nil
+}
+
+// authorize loads the subject's role in the organization and checks the
+// action. Organizations the subject cannot see are reported as missing.
+func (s *organizationService) authorize(subject policy.Subject, action policy.Action, organizationId uuid.UUID) (policy.Subject, error) {
+	subject, err := withMembership(s.repo, subject, organizationId)
+	if err != nil {
+		return policy.Subject{}, err
+	}
+
+	if !policy.CanAccessOrganization(subject, action, organizationId) {
+		return policy.Subject{}, serviceError(errOrganizationNotFound, codes.NotFound)
+	}
+
+	return subject, nil
+}
+
+func (s *organizationService) getMember(organizationId, userId uuid.UUID) (models.OrganizationMember, error) {
+	member, err := s.repo.Organization.GetMember(organizationId, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrganizationMember{}, serviceError(errMemberNotFound, codes.NotFound)
+		}
+		return models.OrganizationMember{}, serviceError(err, codes.Internal)
+	}
+
+	return member, nil
+}
+
+func (s *organizationService) ensureAnotherOwner(organizationId uuid.UUID) error {
+	owners, err := s.repo.Organization.CountOwners(organizationId)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if owners <= 1 {
+		return serviceError(errLastOwner, codes.InvalidArgument)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
+	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/validator"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	errWrongPassword = errors.New("error: Current password is incorrect")
+	errSamePassword  = errors.New("error: New password must differ from the current one")
+	errUsernameTaken = errors.New("error: Username already exists")
+	errEmailTaken    = errors.New("error: Email already exists")
+)
+
+type profileService struct {
+	repo   *repository.Repository
+	cache  *cache.RedisCache
+	auth   Authorization
+	logger *logger.Logger
+	cfg    *config.Config
+
+	passwords *validator.PasswordPolicy
+}
+
+func NewProfileService(repo *repository.Repository, cache *cache.RedisCache, auth Authorization, passwords *validator.PasswordPolicy, logger *logger.Logger, cfg *config.Config) *profileService {
+	return &profileService{
+		repo:      repo,
+		cache:     cache,
+		auth:      auth,
+		logger:    logger,
+		cfg:       cfg,
+		passwords: passwords,
+	}
+}
+
+func (s *profileService) GetProfile(userId uuid.UUID) (models.User, error) {
+	user, err := s.repo.User.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, serviceError(errUserNotFound, codes.NotFound)
+		}
+		return models.User{}, serviceError(err, codes.Internal)
+	}
+
+	return user, nil
+}
+
+// UpdateProfile changes the username and email. Both identify the account
+// when signing in or resetting the password, so a change signs the user out
+// everywhere and returns a fresh token pair for the current client. A new
+// email has to be verified again.
+func (s *profileService) UpdateProfile(userId uuid.UUID, request models.UpdateProfile, client models.Client) (models.User, *models.Token, *models.Token, error) {
+	user, err := s.GetProfile(userId)
+	if err != nil {
+		return models.User{}, nil, nil, err
+	}
+
+	username, email := user.Username, user.Email
+	if request.Username != nil {
+		username = strings.TrimSpace(*request.Username)
+	}
+	if request.Email != nil {
+		email = strings.TrimSpace(*request.Email)
+	}
+
+	if username == "" {
+		return models.User{}, nil, nil, serviceError(errors.New("error: Username is required"), codes.InvalidArgument)
+	}
+
+	if username == user.Username && email == user.Email {
+		return user, nil, nil, nil
+	}
+
+	if username != user.Username {
+		if err = s.ensureAvailable(s.repo.User.GetByUsername, username, userId, errUsernameTaken); err != nil {
+			return models.User{}, nil, nil, err
+		}
+	}
+
+	if email != user.Email {
+		if err = s.ensureAvailable(s.repo.User.GetByEmail, email, userId, errEmailTaken); err != nil {
+			return models.User{}, nil, nil, err
+		}
+	}
+
+	if err = s.repo.User.UpdateProfile(userId, username, email); err != nil {
+		return models.User{}, nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if email != user.Email {
+		if err = s.auth.ResendVerificationEmail(userId); err != nil {
+			return models.User{}, nil, nil, err
+		}
+	}
+
+	if user, err = s.GetProfile(userId); err != nil {
+		return models.User{}, nil, nil, err
+	}
+
+	accessToken, refreshToken, err := s.reissueTokens(user, client)
+	if err != nil {
+		return models.User{}, nil, nil, err
+	}
+
+	return user, accessToken, refreshToken, nil
+}
+
+// ChangePassword replaces the password after checking the current one. Like
+// a password reset it revokes every token and API key of the user; the
+// current client gets a new token pair.
+func (s *profileService) ChangePassword(userId uuid.UUID, request models.ChangePassword, client models.Client) (*models.Token, *models.Token, error) {
+	user, err := s.GetProfile(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	match, _, err := helper.ComparePassword(user.Password, request.OldPassword)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if !match {
+		return nil, nil, serviceError(errWrongPassword, codes.InvalidArgument)
+	}
+
+	if request.NewPassword == request.OldPassword {
+		return nil, nil, serviceError(errSamePassword, codes.InvalidArgument)
+	}
+
+	if err = s.passwords.Validate("new_password", request.NewPassword, user.Username, user.Email); err != nil {
+		return nil, nil, serviceError(err, codes.InvalidArgument)
+	}
+
+	hash, err := helper.GenerateHash(request.NewPassword)
+	if err != nil {
+		return nil, nil, serviceError(err, codes.InvalidArgument)
+	}
+
+	if err = s.repo.User.UpdatePassword(userId, hash); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	if err = revokeUserTokens(s.repo, s.cache, s.cfg, userId); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	return s.auth.GenerateTokens(user, client)
+}
+
+func (s *profileService) reissueTokens(user models.User, client models.Client) (*models.Token, *models.Token, error) {
+	if err := revokeUserSessions(s.repo, s.cache, s.cfg, user.Id); err != nil {
+		return nil, nil, serviceError(err, codes.Internal)
+	}
+
+	return s.auth.GenerateTokens(user, client)
+}
+
+// ensureAvailable fails when value already belongs to another user
+func (s *profileService) ensureAvailable(lookup func(string) (models.User, error), value string, userId uuid.UUID, taken error) error {
+	other, err := lookup(value)
+	if err == nil {
+		if other.Id != userId {
+			return serviceError(taken, codes.AlreadyExists)
+		}
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
@@ -3,9 +3,15 @@ package service
 import (
 	"tender-bridge/config"
 	"tender-bridge/internal/cache"
+	"tender-bridge/internal/mailer"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
 	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/jwks"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/oidc"
+	"tender-bridge/pkg/sealer"
+	"tender-bridge/pkg/validator"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,14 +22,32 @@ type Service struct {
 	Authorization
 	Tender
 	Bid
+	MFA
+	Organization
+	APIKey
+	OIDC
+	Session
+	Profile
+	Clarification
+	Category
 }
 
-func NewService(repos *repository.Repository, cache *cache.RedisCache, cfg *config.Config, loggers *logger.Logger) *Service {
+func NewService(repos *repository.Repository, cache *cache.RedisCache, mailer mailer.Sender, keys *jwks.KeySet, providers map[string]*oidc.Provider, passwords *validator.PasswordPolicy, bids *sealer.Sealer, cfg *config.Config, loggers *logger.Logger) *Service {
+	auth := NewAuthService(repos, cache, mailer, keys, passwords, loggers, cfg)
+
 	return &Service{
-		Authorization: NewAuthService(repos, loggers, cfg),
-		User:          NewUserService(repos, loggers),
-		Tender:        NewTenderService(repos, cache, loggers),
-		Bid:           NewBidService(repos, cache, loggers),
+		Authorization: auth,
+		User:          NewUserService(repos, cache, passwords, loggers, cfg),
+		Tender:        NewTenderService(repos, cache, bids, loggers),
+		Bid:           NewBidService(repos, cache, bids, loggers),
+		MFA:           NewMFAService(repos, cache, loggers, cfg),
+		Organization:  NewOrganizationService(repos, mailer, loggers, cfg),
+		APIKey:        NewAPIKeyService(repos, loggers),
+		OIDC:          NewOIDCService(repos, cache, auth, providers, loggers, cfg),
+		Session:       NewSessionService(repos, cache, loggers, cfg),
+		Profile:       NewProfileService(repos, cache, auth, passwords, loggers, cfg),
+		Clarification: NewClarificationService(repos, loggers, cfg),
+		Category:      NewCategoryService(repos, loggers),
 	}
 }
 
@@ -32,31 +56,107 @@ type User interface {
 	GetUsers(filter models.UserFilter) ([]models.User, int, error)
 	GetUser(id uuid.UUID) (models.User, error)
 	UpdateUser(request models.UpdateUser) error
+	ChangeUserRole(id uuid.UUID, role string) error
+	SuspendUser(id uuid.UUID) error
+	UnsuspendUser(id uuid.UUID) error
+	UnlockUser(actorId, id uuid.UUID) error
 	DeleteUser(id uuid.UUID) error
 }
 
 type Authorization interface {
 	CreateToken(user models.User, tokenType string, expiresAt time.Time) (*models.Token, error)
-	GenerateTokens(user models.User) (*models.Token, *models.Token, error)
+	GenerateTokens(user models.User, client models.Client) (*models.Token, *models.Token, error)
 	ParseToken(token string) (*jwtCustomClaim, error)
-	Login(request models.Login) (*models.Token, *models.Token, error)
-	Register(request models.Register) (*models.Token, *models.Token, error)
+	PublicKeys() jwks.JSONWebKeySet
+	RefreshTokens(refreshToken string, client models.Client) (*models.Token, *models.Token, error)
+	Logout(accessToken, refreshToken string) error
+	IsTokenRevoked(claims *jwtCustomClaim) (bool, error)
+	RevokeUserTokens(userId uuid.UUID) error
+	RequestPasswordReset(request models.PasswordResetRequest) error
+	ResetPassword(request models.PasswordResetConfirm) error
+	VerifyEmail(request models.VerifyEmail) error
+	ResendVerificationEmail(userId uuid.UUID) error
+	Login(request models.Login, client models.Client) (*models.Token, *models.Token, error)
+	VerifyMFA(request models.LoginMFA, client models.Client) (*models.Token, *models.Token, error)
+	Register(request models.Register, client models.Client) (*models.Token, *models.Token, error)
 }
 
 type Tender interface {
-	CreateTender(request models.CreateTender) (uuid.UUID, error)
-	GetTenders(filter models.TenderFilter) ([]models.Tender, int, error)
-	GetTender(id uuid.UUID) (models.Tender, error)
-	UpdateTender(request models.UpdateTender) error
-	DeleteTender(id uuid.UUID) error
-	UpdateTenderStatus(request models.UpdateTenderStatus) error
+	CreateTender(subject policy.Subject, request models.CreateTender) (uuid.UUID, error)
+	GetTenders(filter models.TenderFilter) ([]models.Tender, models.PageCursors, error)
+	GetTender(subject policy.Subject, id uuid.UUID) (models.Tender, error)
+	AmendTender(subject policy.Subject, request models.AmendTender) (models.TenderRevision, error)
+	GetTenderRevisions(subject policy.Subject, id uuid.UUID) ([]models.TenderRevision, error)
+	DeleteTender(subject policy.Subject, id uuid.UUID) error
+	UpdateTenderStatus(subject policy.Subject, request models.UpdateTenderStatus) error
+	GetTenderHistory(subject policy.Subject, id uuid.UUID) ([]models.TenderStatusChange, error)
+	CloseExpiredTenders() (int, error)
 }
 
 type Bid interface {
-	SubmitBid(request models.CreateBid) (uuid.UUID, error)
-	GetBids(filter models.BidFilter) ([]models.Bid, int, error)
+	SubmitBid(subject policy.Subject, request models.CreateBid) (uuid.UUID, error)
+	GetBids(filter models.BidFilter) ([]models.Bid, models.PageCursors, error)
+	GetMemberBids(subject policy.Subject, filter models.BidFilter) ([]models.Bid, models.PageCursors, error)
+	GetTenderBids(subject policy.Subject, filter models.BidFilter) ([]models.Bid, models.PageCursors, *models.SealedBids, error)
 	GetBid(id uuid.UUID) (models.Bid, error)
 	UpdateBid(request models.UpdateBid) error
-	DeleteContractorBid(contractorId, bidId uuid.UUID) error
-	AwardBid(clientId, tenderId, bidId uuid.UUID) error
+	DeleteContractorBid(subject policy.Subject, bidId uuid.UUID) error
+	AwardBid(subject policy.Subject, tenderId, bidId uuid.UUID) error
+	AcknowledgeAmendment(subject policy.Subject, bidId uuid.UUID) error
+}
+
+type MFA interface {
+	EnrollTOTP(userId uuid.UUID) (models.TOTPEnrollment, error)
+	ConfirmTOTP(userId uuid.UUID, code string) ([]string, error)
+	DisableTOTP(userId uuid.UUID, code string) error
+}
+
+type Organization interface {
+	CreateOrganization(userId uuid.UUID, request models.CreateOrganization) (uuid.UUID, error)
+	GetOrganizations(userId uuid.UUID) ([]models.Organization, error)
+	GetOrganization(subject policy.Subject, id uuid.UUID) (models.Organization, error)
+	GetMembers(subject policy.Subject, id uuid.UUID) ([]models.OrganizationMember, error)
+	UpdateMemberRole(subject policy.Subject, organizationId, userId uuid.UUID, role string) error
+	RemoveMember(subject policy.Subject, organizationId, userId uuid.UUID) error
+	InviteMember(subject policy.Subject, organizationId uuid.UUID, request models.InviteMember) error
+	AcceptInvitation(userId uuid.UUID, request models.AcceptInvitation) error
+}
+
+type APIKey interface {
+	CreateAPIKey(subject policy.Subject, request models.CreateAPIKey) (models.CreatedAPIKey, error)
+	GetAPIKeys(userId uuid.UUID) ([]models.APIKey, error)
+	RevokeAPIKey(userId, id uuid.UUID) error
+	AuthenticateAPIKey(key string) (models.User, []string, error)
+}
+
+type OIDC interface {
+	GetProviders() []string
+	LoginURL(provider string) (string, error)
+	Callback(provider, code, state string, client models.Client) (*models.Token, *models.Token, error)
+}
+
+type Session interface {
+	GetSessions(userId uuid.UUID, currentId *uuid.UUID) ([]models.Session, error)
+	RevokeSession(userId, id uuid.UUID) error
+}
+
+type Profile interface {
+	GetProfile(userId uuid.UUID) (models.User, error)
+	UpdateProfile(userId uuid.UUID, request models.UpdateProfile, client models.Client) (models.User, *models.Token, *models.Token, error)
+	ChangePassword(userId uuid.UUID, request models.ChangePassword, client models.Client) (*models.Token, *models.Token, error)
+}
+
+type Clarification interface {
+	AskQuestion(subject policy.Subject, request models.CreateClarification) (uuid.UUID, error)
+	GetClarifications(subject policy.Subject, tenderId uuid.UUID) ([]models.Clarification, error)
+	AnswerQuestion(subject policy.Subject, request models.AnswerClarification) error
+}
+
+type Category interface {
+	CreateCategory(request models.CreateCategory) (uuid.UUID, error)
+	GetCategories() ([]models.Category, error)
+	DeleteCategory(id uuid.UUID) error
+	GetSubscriptions(userId uuid.UUID) ([]models.Category, error)
+	Subscribe(userId, categoryId uuid.UUID) error
+	Unsubscribe(userId, categoryId uuid.UUID) error
 }
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
+	"tender-bridge/internal/models"
+	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/logger"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+var errSessionNotFound = errors.New("error: Session not found")
+
+type sessionService struct {
+	repo   *repository.Repository
+	cache  *cache.RedisCache
+	logger *logger.Logger
+	cfg    *config.Config
+}
+
+func NewSessionService(repo *repository.Repository, cache *cache.RedisCache, logger *logger.Logger, cfg *config.Config) *sessionService {
+	return &sessionService{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// GetSessions lists where the user is signed in, marking the session the
+// request was made with
+func (s *sessionService) GetSessions(userId uuid.UUID, currentId *uuid.UUID) ([]models.Session, error) {
+	sessions, err := s.repo.Session.GetActiveByUser(userId)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	if currentId != nil {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].Id == *currentId
+		}
+	}
+
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(userId, id uuid.UUID) error {
+	revoked, err := revokeSession(s.repo, s.cache, s.cfg, userId, id)
+	if err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if !revoked {
+		return serviceError(errSessionNotFound, codes.NotFound)
+	}
+
+	return nil
+}
@@ -1,34 +1,56 @@
 package service
 
 import (
-	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
 	"tender-bridge/config"
 	"tender-bridge/internal/cache"
+	"tender-bridge/internal/lifecycle"
 	"tender-bridge/internal/models"
+	"tender-bridge/internal/policy"
 	"tender-bridge/internal/repository"
+	"tender-bridge/internal/ws"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/sealer"
 	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 )
 
+var (
+	errTenderStatusChanged = errors.New("error: The tender status was changed by someone else, reload and try again")
+	errInvalidCursor       = errors.New("error: The cursor does not belong to this list")
+)
+
 type tenderService struct {
 	repo   *repository.Repository
 	cache  *cache.RedisCache
+	sealer *sealer.Sealer
 	logger *logger.Logger
 }
 
-func NewTenderService(repo *repository.Repository, cache *cache.RedisCache, logger *logger.Logger) *tenderService {
+func NewTenderService(repo *repository.Repository, cache *cache.RedisCache, sealer *sealer.Sealer, logger *logger.Logger) *tenderService {
 	return &tenderService{
 		repo:   repo,
 		cache:  cache,
+		sealer: sealer,
 		logger: logger,
 	}
 }
 
-func (s *tenderService) CreateTender(request models.CreateTender) (uuid.UUID, error) {
+func (s *tenderService) CreateTender(subject policy.Subject, request models.CreateTender) (uuid.UUID, error) {
+	if err := ensureEmailVerified(s.repo, request.ClientId); err != nil {
+		return uuid.Nil, err
+	}
+
+	organizationId, err := resolveOrganization(s.repo, subject, policy.TenderCreate, request.OrganizationId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	request.OrganizationId = organizationId
+
 	deadlineTime, err := time.Parse(time.RFC3339, request.Deadline)
 	if err != nil {
 		return uuid.Nil, serviceError(err, codes.Internal)
@@ -41,13 +63,30 @@ func (s *tenderService) CreateTender(request models.CreateTender) (uuid.UUID, er
 		return uuid.Nil, serviceError(errors.New("error: Invalid tender data"), codes.InvalidArgument)
 	}
 
-	request.Status = config.TenderStatusOpen
+	if request.CategoryId != nil {
+		if _, err = getCategory(s.repo, *request.CategoryId); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if request.Tags, err = normalizeTags(request.Tags); err != nil {
+		return uuid.Nil, err
+	}
+
+	request.Status = config.TenderStatusPublished
+	if request.Draft {
+		request.Status = config.TenderStatusDraft
+	}
 
 	id, err := s.repo.Tender.Create(request)
 	if err != nil {
 		return uuid.Nil, serviceError(err, codes.Internal)
 	}
 
+	if request.Status == config.TenderStatusPublished {
+		s.notifySubscribers(request.Title, request.CategoryId)
+	}
+
 	go func() {
 		if err := s.cache.DeletePattern("tender_list*"); err != nil {
 			s.logger.Error(err)
@@ -57,18 +96,53 @@ func (s *tenderService) CreateTender(request models.CreateTender) (uuid.UUID, er
 	return id, nil
 }
 
-func (s *tenderService) GetTenders(filter models.TenderFilter) ([]models.Tender, int, error) {
+// GetTenders lists a page of tenders. Pages start at the filter's cursor
+// when it has one and at its offset otherwise.
+func (s *tenderService) GetTenders(filter models.TenderFilter) ([]models.Tender, models.PageCursors, error) {
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return nil, models.PageCursors{}, err
+	}
+	filter.Tags = tags
+
+	if err = validateTenderFilter(&filter); err != nil {
+		return nil, models.PageCursors{}, err
+	}
+
+	// results ranked by relevance have no sort key to page from
+	key := tenderCursor(filter)
+	if filter.Search != "" && filter.Sort == "" {
+		key = nil
+	}
+
+	if filter.Cursor != nil {
+		if key == nil {
+			return nil, models.PageCursors{}, serviceError(errors.New("error: Sort search results to page them with a cursor"), codes.InvalidArgument)
+		}
+
+		if filter.Cursor.Sort != filter.Sort || filter.Cursor.Order != filter.Order || !validCursorValue(filter.Sort, filter.Cursor.Value) {
+			return nil, models.PageCursors{}, serviceError(errInvalidCursor, codes.InvalidArgument)
+		}
+
+		filter.Offset = 0
+	}
+
+	// one more row tells whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+
 	cacheKey := generateCacheKeyTender(filter)
 	var tenders []models.Tender
 
 	if err := s.cache.Get(cacheKey, &tenders); err == nil {
 		s.logger.Info("get tenders from cache")
-		return tenders, 0, nil
+		tenders, cursors := paginate(tenders, limit, filter.Offset, filter.Cursor, key)
+		return tenders, cursors, nil
 	}
 
-	tenders, total, err := s.repo.Tender.GetList(filter)
+	tenders, _, err = s.repo.Tender.GetList(filter)
 	if err != nil {
-		return nil, 0, serviceError(err, codes.Internal)
+		return nil, models.PageCursors{}, serviceError(err, codes.Internal)
 	}
 
 	clientIds := make([]uuid.UUID, len(tenders))
@@ -78,7 +152,7 @@ func (s *tenderService) GetTenders(filter models.TenderFilter) ([]models.Tender,
 
 	clients, err := s.repo.User.GetByIds(clientIds)
 	if err != nil {
-		return nil, 0, serviceError(err, codes.Internal)
+		return nil, models.PageCursors{}, serviceError(err, codes.Internal)
 	}
 
 	clientsMap := make(map[uuid.UUID]models.User, len(clients))
@@ -96,13 +170,78 @@ func (s *tenderService) GetTenders(filter models.TenderFilter) ([]models.Tender,
 		}
 	}()
 
-	return tenders, total, nil
+	tenders, cursors := paginate(tenders, limit, filter.Offset, filter.Cursor, key)
+
+	return tenders, cursors, nil
 }
 
-func (s *tenderService) GetTender(id uuid.UUID) (models.Tender, error) {
-	tender, err := s.repo.Tender.GetById(id)
+// tenderCursor makes cursors for the tender list sorted as in the filter
+func tenderCursor(filter models.TenderFilter) func(models.Tender) models.Cursor {
+	return func(tender models.Tender) models.Cursor {
+		cursor := models.Cursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Id:    tender.Id,
+		}
+
+		switch filter.Sort {
+		case config.TenderSortBudget:
+			cursor.Value = strconv.FormatInt(tender.Budget, 10)
+		case config.TenderSortDeadline:
+			cursor.Value = tender.Deadline.Format(time.RFC3339Nano)
+		default:
+			cursor.Value = tender.CreatedAt.Format(time.RFC3339Nano)
+		}
+
+		return cursor
+	}
+}
+
+// validateTenderFilter rejects unknown statuses and sort fields and empty
+// ranges, and fills in the default sort order
+func validateTenderFilter(filter *models.TenderFilter) error {
+	if filter.Status != "" && !lifecycle.IsTenderStatus(filter.Status) {
+		return serviceError(errors.New("error: Invalid tender status"), codes.InvalidArgument)
+	}
+
+	if filter.MinBudget != nil && filter.MaxBudget != nil && *filter.MinBudget > *filter.MaxBudget {
+		return serviceError(errors.New("error: Minimum budget is greater than the maximum"), codes.InvalidArgument)
+	}
+
+	if filter.DeadlineAfter != nil && filter.DeadlineBefore != nil && filter.DeadlineAfter.After(*filter.DeadlineBefore) {
+		return serviceError(errors.New("error: Invalid deadline range"), codes.InvalidArgument)
+	}
+
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && filter.CreatedAfter.After(*filter.CreatedBefore) {
+		return serviceError(errors.New("error: Invalid creation date range"), codes.InvalidArgument)
+	}
+
+	switch filter.Sort {
+	case "", config.TenderSortBudget, config.TenderSortDeadline, config.TenderSortCreatedAt:
+	default:
+		return serviceError(errors.New("error: Tenders can be sorted by budget, deadline or created_at"), codes.InvalidArgument)
+	}
+
+	switch filter.Order {
+	case "":
+		if filter.Sort != "" {
+			filter.Order = config.SortAsc
+		}
+	case config.SortAsc, config.SortDesc:
+		if filter.Sort == "" {
+			return serviceError(errors.New("error: Sort order needs a sort field"), codes.InvalidArgument)
+		}
+	default:
+		return serviceError(errors.New("error: Sort order must be asc or desc"), codes.InvalidArgument)
+	}
+
+	return nil
+}
+
+func (s *tenderService) GetTender(subject policy.Subject, id uuid.UUID) (models.Tender, error) {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderRead, id)
 	if err != nil {
-		return models.Tender{}, serviceError(err, codes.Internal)
+		return models.Tender{}, err
 	}
 
 	tender.Client, err = s.repo.User.GetById(tender.ClientId)
@@ -113,13 +252,92 @@ func (s *tenderService) GetTender(id uuid.UUID) (models.Tender, error) {
 	return tender, nil
 }
 
-func (s *tenderService) UpdateTender(request models.UpdateTender) error {
-	if request.Status != config.TenderStatusAwarded && request.Status != config.TenderStatusClosed && request.Status != config.TenderStatusOpen {
-		return serviceError(errors.New("invalid tender status"), codes.InvalidArgument)
+// AmendTender changes the terms of a draft or published tender and stores
+// the change as a new revision. Bidders are notified of amendments to
+// published tenders, and material ones must be acknowledged before their bid
+// can be awarded.
+func (s *tenderService) AmendTender(subject policy.Subject, request models.AmendTender) (models.TenderRevision, error) {
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderUpdate, request.Id)
+	if err != nil {
+		return models.TenderRevision{}, err
 	}
 
-	if err := s.repo.Tender.Update(request); err != nil {
-		return serviceError(err, codes.Internal)
+	if tender.Status != config.TenderStatusDraft && tender.Status != config.TenderStatusPublished {
+		return models.TenderRevision{}, serviceError(errors.New("error: Only draft and published tenders can be amended"), codes.InvalidArgument)
+	}
+
+	revision := models.CreateTenderRevision{
+		TenderId:    tender.Id,
+		Base:        tender.Revision,
+		Title:       tender.Title,
+		Description: tender.Description,
+		Deadline:    tender.Deadline,
+		Budget:      tender.Budget,
+		File:        tender.File,
+		Changes:     map[string]models.FieldChange{},
+		Reason:      request.Reason,
+		ChangedBy:   &subject.Id,
+	}
+
+	if request.Title != nil && *request.Title != tender.Title {
+		if *request.Title == "" {
+			return models.TenderRevision{}, serviceError(errors.New("error: Title is required"), codes.InvalidArgument)
+		}
+		revision.Changes["title"] = models.FieldChange{From: tender.Title, To: *request.Title}
+		revision.Title = *request.Title
+	}
+
+	if request.Description != nil && *request.Description != tender.Description {
+		if *request.Description == "" {
+			return models.TenderRevision{}, serviceError(errors.New("error: Description is required"), codes.InvalidArgument)
+		}
+		revision.Changes["description"] = models.FieldChange{From: tender.Description, To: *request.Description}
+		revision.Description = *request.Description
+		revision.Material = true
+	}
+
+	if request.Deadline != nil {
+		deadline, err := time.Parse(time.RFC3339, *request.Deadline)
+		if err != nil {
+			return models.TenderRevision{}, serviceError(errors.New("error: Invalid deadline"), codes.InvalidArgument)
+		}
+
+		if !deadline.Equal(tender.Deadline) {
+			if !deadline.After(time.Now()) {
+				return models.TenderRevision{}, serviceError(errors.New("error: Deadline must be in the future"), codes.InvalidArgument)
+			}
+			revision.Changes["deadline"] = models.FieldChange{From: tender.Deadline, To: deadline}
+			revision.Deadline = deadline
+			revision.Material = true
+		}
+	}
+
+	if request.Budget != nil && *request.Budget != tender.Budget {
+		if *request.Budget < 0 {
+			return models.TenderRevision{}, serviceError(errors.New("error: Budget cannot be negative"), codes.InvalidArgument)
+		}
+		revision.Changes["budget"] = models.FieldChange{From: tender.Budget, To: *request.Budget}
+		revision.Budget = *request.Budget
+		revision.Material = true
+	}
+
+	if request.File != nil && *request.File != tender.File {
+		revision.Changes["file"] = models.FieldChange{From: tender.File, To: *request.File}
+		revision.File = *request.File
+		revision.Material = true
+	}
+
+	if len(revision.Changes) == 0 {
+		return models.TenderRevision{}, serviceError(errors.New("error: The amendment changes nothing"), codes.InvalidArgument)
+	}
+
+	amended, changed, err := s.repo.Tender.Amend(revision)
+	if err != nil {
+		return models.TenderRevision{}, serviceError(err, codes.Internal)
+	}
+
+	if !changed {
+		return models.TenderRevision{}, serviceError(errors.New("error: The tender was amended or changed its status in the meantime, reload and try again"), codes.Aborted)
 	}
 
 	go func() {
@@ -128,15 +346,68 @@ func (s *tenderService) UpdateTender(request models.UpdateTender) error {
 		}
 	}()
 
-	return nil
+	if tender.Status == config.TenderStatusPublished {
+		s.notifyBidders(tender, amended)
+	}
+
+	return amended, nil
 }
 
-func (s *tenderService) DeleteTender(id uuid.UUID) error {
-	_, err := s.repo.Tender.GetById(id)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return serviceError(err, codes.Internal)
-	} else if errors.Is(err, sql.ErrNoRows) {
-		return serviceError(errors.New("Tender not found or access denied"), codes.NotFound)
+func (s *tenderService) GetTenderRevisions(subject policy.Subject, id uuid.UUID) ([]models.TenderRevision, error) {
+	if _, err := getAuthorizedTender(s.repo, subject, policy.TenderRead, id); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.Tender.GetRevisions(id)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return revisions, nil
+}
+
+// notifySubscribers tells the users following the category, or one of its
+// parents, about a newly published tender
+func (s *tenderService) notifySubscribers(title string, categoryId *uuid.UUID) {
+	if categoryId == nil {
+		return
+	}
+
+	subscriberIds, err := s.repo.Category.GetSubscriberIds(*categoryId)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+
+	go func() {
+		for _, id := range subscriberIds {
+			ws.BroadcastNotification(id.String(), fmt.Sprintf("New tender %q in a category you follow", title))
+		}
+	}()
+}
+
+func (s *tenderService) notifyBidders(tender models.Tender, revision models.TenderRevision) {
+	bidderIds, err := s.repo.Bid.GetBidderIds(tender.Id)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+
+	message := fmt.Sprintf("Tender %q was amended", tender.Title)
+	if revision.Material {
+		message += ", acknowledge the amendment to keep your bid eligible"
+	}
+
+	go func() {
+		for _, id := range bidderIds {
+			ws.BroadcastNotification(id.String(), message)
+		}
+	}()
+}
+
+func (s *tenderService) DeleteTender(subject policy.Subject, id uuid.UUID) error {
+	if _, err := getAuthorizedTender(s.repo, subject, policy.TenderDelete, id); err != nil {
+		return err
 	}
 
 	if err := s.repo.Tender.Delete(id); err != nil {
@@ -152,34 +423,129 @@ func (s *tenderService) DeleteTender(id uuid.UUID) error {
 	return nil
 }
 
-func (s *tenderService) UpdateTenderStatus(request models.UpdateTenderStatus) error {
-	if request.Status != config.TenderStatusAwarded && request.Status != config.TenderStatusClosed && request.Status != config.TenderStatusOpen {
+// UpdateTenderStatus moves the tender along its lifecycle. Awarding goes
+// through the bid award endpoint since it needs a winning bid.
+func (s *tenderService) UpdateTenderStatus(subject policy.Subject, request models.UpdateTenderStatus) error {
+	if !lifecycle.IsTenderStatus(request.Status) {
 		return serviceError(errors.New("error: Invalid tender status"), codes.InvalidArgument)
 	}
 
-	tender, err := s.repo.Tender.GetById(request.Id)
+	if request.Status == config.TenderStatusAwarded {
+		return serviceError(errors.New("error: Award a bid to award the tender"), codes.InvalidArgument)
+	}
+
+	tender, err := getAuthorizedTender(s.repo, subject, policy.TenderUpdate, request.Id)
 	if err != nil {
-		return serviceError(err, codes.InvalidArgument)
+		return err
 	}
 
-	if err := s.repo.Tender.Update(models.UpdateTender{
-		Id:          request.Id,
-		ClientId:    tender.ClientId,
-		Title:       tender.Title,
-		Description: tender.Description,
-		Deadline:    tender.Deadline,
-		Budget:      tender.Budget,
-		File:        tender.File,
-		Status:      request.Status,
-	}); err != nil {
+	if !lifecycle.CanTransitionTender(tender.Status, request.Status) {
+		return serviceError(fmt.Errorf("error: Cannot change tender status from %s to %s", tender.Status, request.Status), codes.InvalidArgument)
+	}
+
+	if err = s.checkTransition(tender, request.Status); err != nil {
+		return err
+	}
+
+	changed, err := s.repo.Tender.Transition(models.TenderTransition{
+		TenderId:  tender.Id,
+		From:      tender.Status,
+		To:        request.Status,
+		ChangedBy: &subject.Id,
+		Reason:    request.Reason,
+	})
+	if err != nil {
 		return serviceError(err, codes.Internal)
 	}
 
+	if !changed {
+		return serviceError(errTenderStatusChanged, codes.Aborted)
+	}
+
 	go func() {
 		if err := s.cache.DeletePattern("tender_list*"); err != nil {
 			s.logger.Error(err)
 		}
 	}()
 
+	if request.Status == config.TenderStatusPublished {
+		s.notifySubscribers(tender.Title, tender.CategoryId)
+	}
+
+	// a failed opening is retried when the bids are listed or awarded
+	tender.Status = request.Status
+	if err = openSealedBids(s.repo, s.sealer, tender, &subject.Id); err != nil {
+		s.logger.Error(err)
+	}
+
+	return nil
+}
+
+func (s *tenderService) GetTenderHistory(subject policy.Subject, id uuid.UUID) ([]models.TenderStatusChange, error) {
+	if _, err := getAuthorizedTender(s.repo, subject, policy.TenderHistory, id); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.Tender.GetHistory(id)
+	if err != nil {
+		return nil, serviceError(err, codes.Internal)
+	}
+
+	return history, nil
+}
+
+// CloseExpiredTenders closes the published tenders whose deadline has passed
+// and tells their clients that bidding is over. It returns how many tenders
+// were closed.
+func (s *tenderService) CloseExpiredTenders() (int, error) {
+	tenders, err := s.repo.Tender.CloseExpired(time.Now().UTC())
+	if err != nil {
+		return 0, serviceError(err, codes.Internal)
+	}
+
+	if len(tenders) == 0 {
+		return 0, nil
+	}
+
+	if err = s.cache.DeletePattern("tender_list*"); err != nil {
+		s.logger.Error(err)
+	}
+
+	for _, tender := range tenders {
+		if err = openSealedBids(s.repo, s.sealer, tender, nil); err != nil {
+			s.logger.Error(err)
+		}
+	}
+
+	go func() {
+		for _, tender := range tenders {
+			ws.BroadcastNotification(tender.ClientId.String(), fmt.Sprintf("Bidding is over for tender %q", tender.Title))
+		}
+	}()
+
+	return len(tenders), nil
+}
+
+// checkTransition applies the guard conditions of the target status
+func (s *tenderService) checkTransition(tender models.Tender, to string) error {
+	switch to {
+	case config.TenderStatusPublished:
+		if !tender.Deadline.After(time.Now()) {
+			return serviceError(errors.New("error: Move the deadline to the future before publishing"), codes.InvalidArgument)
+		}
+	case config.TenderStatusUnderEvaluation:
+		_, total, err := s.repo.Bid.GetList(models.BidFilter{
+			TenderId: tender.Id,
+			Limit:    1,
+		})
+		if err != nil {
+			return serviceError(err, codes.Internal)
+		}
+
+		if total == 0 {
+			return serviceError(errors.New("error: The tender has no bids to evaluate"), codes.InvalidArgument)
+		}
+	}
+
 	return nil
 }
@@ -1,32 +1,65 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
+	"tender-bridge/config"
+	"tender-bridge/internal/cache"
 	"tender-bridge/internal/models"
 	"tender-bridge/internal/repository"
+	"tender-bridge/pkg/helper"
 	"tender-bridge/pkg/logger"
+	"tender-bridge/pkg/validator"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 )
 
+var errUserNotFound = errors.New("error: User not found")
+
 type userService struct {
 	repo   *repository.Repository
+	cache  *cache.RedisCache
 	logger *logger.Logger
+	cfg    *config.Config
+
+	passwords *validator.PasswordPolicy
 }
 
-func NewUserService(repo *repository.Repository, logger *logger.Logger) *userService {
+func NewUserService(repo *repository.Repository, cache *cache.RedisCache, passwords *validator.PasswordPolicy, logger *logger.Logger, cfg *config.Config) *userService {
 	return &userService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		cache:     cache,
+		logger:    logger,
+		cfg:       cfg,
+		passwords: passwords,
 	}
 }
 
 func (s *userService) CreateUser(request models.CreateUser) (uuid.UUID, error) {
+	if !isValidRole(request.Role) {
+		return uuid.Nil, serviceError(errors.New("invalid role"), codes.InvalidArgument)
+	}
+
+	if err := s.passwords.Validate("password", request.Password, request.Username, request.Email); err != nil {
+		return uuid.Nil, serviceError(err, codes.InvalidArgument)
+	}
+
+	var err error
+	request.Password, err = helper.GenerateHash(request.Password)
+	if err != nil {
+		return uuid.Nil, serviceError(err, codes.InvalidArgument)
+	}
+
 	id, err := s.repo.User.Create(request)
 	if err != nil {
 		return uuid.Nil, serviceError(err, codes.Internal)
 	}
 
+	if err = createPersonalOrganization(s.repo, id, request.Username, request.Role); err != nil {
+		return uuid.Nil, err
+	}
+
 	return id, nil
 }
 
@@ -42,6 +75,9 @@ func (s *userService) GetUsers(filter models.UserFilter) ([]models.User, int, er
 func (s *userService) GetUser(id uuid.UUID) (models.User, error) {
 	user, err := s.repo.User.GetById(id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, serviceError(errUserNotFound, codes.NotFound)
+		}
 		return models.User{}, serviceError(err, codes.Internal)
 	}
 
@@ -56,10 +92,89 @@ func (s *userService) UpdateUser(request models.UpdateUser) error {
 	return nil
 }
 
+// ChangeUserRole moves the user to another role. The role is embedded in
+// issued tokens, so all of them are revoked.
+func (s *userService) ChangeUserRole(id uuid.UUID, role string) error {
+	if !isValidRole(role) {
+		return serviceError(errors.New("invalid role"), codes.InvalidArgument)
+	}
+
+	if err := s.repo.User.UpdateRole(id, role); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if err := revokeUserTokens(s.repo, s.cache, s.cfg, id); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *userService) SuspendUser(id uuid.UUID) error {
+	if err := s.repo.User.SetSuspended(id, true); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if err := revokeUserTokens(s.repo, s.cache, s.cfg, id); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+func (s *userService) UnsuspendUser(id uuid.UUID) error {
+	if err := s.repo.User.SetSuspended(id, false); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
+// UnlockUser lifts a login lockout of the user before it expires
+func (s *userService) UnlockUser(actorId, id uuid.UUID) error {
+	user, err := s.repo.User.GetById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceError(errUserNotFound, codes.NotFound)
+		}
+		return serviceError(err, codes.Internal)
+	}
+
+	throttle := newLoginThrottle(s.repo, s.cache, s.logger, s.cfg)
+
+	if err = throttle.Reset(user.Username); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if err = throttle.ResetMFA(user.Id); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	if _, err = s.repo.AuditLog.Create(models.CreateAuditLog{
+		Action:  config.AuditActionLoginUnlocked,
+		ActorId: &actorId,
+		UserId:  &user.Id,
+	}); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
+	return nil
+}
+
 func (s *userService) DeleteUser(id uuid.UUID) error {
 	if err := s.repo.User.Delete(id); err != nil {
 		return serviceError(err, codes.Internal)
 	}
 
+	// refresh tokens are removed with the user, but issued access tokens
+	// would stay valid until they expire
+	if err := revokeUserTokens(s.repo, s.cache, s.cfg, id); err != nil {
+		return serviceError(err, codes.Internal)
+	}
+
 	return nil
 }
+
+func isValidRole(role string) bool {
+	return role == config.RoleAdmin || role == config.RoleClient || role == config.RoleContractor
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" TIMESTAMP;
+
+-- accounts created before verification existed are trusted as they are
+UPDATE "users" SET "email_verified_at" = NOW() WHERE "email_verified_at" IS NULL;
+
+CREATE TABLE IF NOT EXISTS "email_verification_tokens"(
+    "id" UUID PRIMARY KEY,
+    "user_id" UUID NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL UNIQUE,
+    "expires_at" TIMESTAMP NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS "email_verification_tokens";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "user_mfa"(
+    "user_id" UUID PRIMARY KEY,
+    "totp_secret" TEXT NOT NULL,
+    "enabled_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "mfa_recovery_codes"(
+    "id" UUID PRIMARY KEY,
+    "user_id" UUID NOT NULL,
+    "code_hash" VARCHAR(64) NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "mfa_recovery_codes_user_id_idx" ON "mfa_recovery_codes"("user_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "mfa_recovery_codes";
+
+DROP TABLE IF EXISTS "user_mfa";
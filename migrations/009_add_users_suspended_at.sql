@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_at" TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_at";
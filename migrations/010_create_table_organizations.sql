@@ -0,0 +1,67 @@
+-- +goose Up
+CREATE TYPE organization_role AS ENUM (
+    'owner',
+    'manager',
+    'viewer'
+);
+
+CREATE TABLE IF NOT EXISTS "organizations"(
+    "id" UUID PRIMARY KEY,
+    "name" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS "organization_members"(
+    "organization_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "role" organization_role NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "organization_members_user_id_idx" ON "organization_members"("user_id");
+
+CREATE TABLE IF NOT EXISTS "organization_invitations"(
+    "id" UUID PRIMARY KEY,
+    "organization_id" UUID NOT NULL,
+    "email" VARCHAR(255) NOT NULL,
+    "role" organization_role NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL UNIQUE,
+    "invited_by" UUID,
+    "expires_at" TIMESTAMP NOT NULL,
+    "accepted_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- every existing client and contractor gets a personal organization that
+-- reuses the user id, so their tenders and bids can be moved over directly
+INSERT INTO "organizations" ("id", "name")
+SELECT "id", "username" FROM "users" WHERE "role" IN ('client', 'contractor');
+
+INSERT INTO "organization_members" ("organization_id", "user_id", "role")
+SELECT "id", "id", 'owner' FROM "users" WHERE "role" IN ('client', 'contractor');
+
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "organization_id" UUID REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE "tenders" SET "organization_id" = "client_id";
+ALTER TABLE "tenders" ALTER COLUMN "organization_id" SET NOT NULL;
+
+ALTER TABLE "bids" ADD COLUMN IF NOT EXISTS "organization_id" UUID REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE "bids" SET "organization_id" = "contractor_id";
+ALTER TABLE "bids" ALTER COLUMN "organization_id" SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE "bids" DROP COLUMN IF EXISTS "organization_id";
+
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS "organization_invitations";
+
+DROP TABLE IF EXISTS "organization_members";
+
+DROP TABLE IF EXISTS "organizations";
+
+DROP TYPE "organization_role";
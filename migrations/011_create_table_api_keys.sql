@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "api_keys"(
+    "id" UUID PRIMARY KEY,
+    "user_id" UUID NOT NULL,
+    "name" VARCHAR(255) NOT NULL,
+    "prefix" VARCHAR(16) NOT NULL,
+    "key_hash" VARCHAR(64) NOT NULL UNIQUE,
+    "scopes" TEXT[] NOT NULL,
+    "expires_at" TIMESTAMP,
+    "last_used_at" TIMESTAMP,
+    "revoked_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "api_keys_user_id_idx" ON "api_keys"("user_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "api_keys";
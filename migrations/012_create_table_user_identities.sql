@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "user_identities"(
+    "id" UUID PRIMARY KEY,
+    "user_id" UUID NOT NULL,
+    "provider" VARCHAR(64) NOT NULL,
+    "subject" VARCHAR(255) NOT NULL,
+    "email" VARCHAR(255),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS "user_identities";
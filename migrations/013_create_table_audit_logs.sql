@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "audit_logs"(
+    "id" UUID PRIMARY KEY,
+    "action" VARCHAR(64) NOT NULL,
+    "actor_id" UUID,
+    "user_id" UUID,
+    "ip_address" VARCHAR(64),
+    "details" TEXT,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS "audit_logs_user_id_idx" ON "audit_logs"("user_id");
+CREATE INDEX IF NOT EXISTS "audit_logs_created_at_idx" ON "audit_logs"("created_at");
+
+-- +goose Down
+DROP TABLE IF EXISTS "audit_logs";
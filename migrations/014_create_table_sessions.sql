@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "sessions"(
+    "id" UUID PRIMARY KEY,
+    "user_id" UUID NOT NULL,
+    "user_agent" TEXT,
+    "ip_address" VARCHAR(64),
+    "expires_at" TIMESTAMP NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "last_used_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "revoked_at" TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "sessions_user_id_idx" ON "sessions"("user_id");
+
+-- every refresh token family started by a login becomes a session
+INSERT INTO "sessions" ("id", "user_id", "expires_at", "created_at", "last_used_at", "revoked_at")
+SELECT
+    family_id,
+    user_id,
+    MAX(expires_at),
+    MIN(created_at),
+    MAX(created_at),
+    CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+ALTER TABLE "refresh_tokens"
+    ADD CONSTRAINT "refresh_tokens_family_id_fkey" FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE "refresh_tokens" DROP CONSTRAINT IF EXISTS "refresh_tokens_family_id_fkey";
+DROP TABLE IF EXISTS "sessions";
@@ -0,0 +1,57 @@
+-- +goose Up
+-- enum values cannot be renamed or removed, so the type is replaced
+CREATE TYPE tender_status_v2 AS ENUM (
+    'draft',
+    'published',
+    'closed',
+    'under_evaluation',
+    'awarded',
+    'cancelled'
+);
+
+ALTER TABLE "tenders"
+    ALTER COLUMN "status" TYPE tender_status_v2
+    USING (CASE "status"::TEXT WHEN 'open' THEN 'published' ELSE "status"::TEXT END)::tender_status_v2;
+
+DROP TYPE tender_status;
+ALTER TYPE tender_status_v2 RENAME TO tender_status;
+
+CREATE TABLE IF NOT EXISTS "tender_status_history"(
+    "id" UUID PRIMARY KEY,
+    "tender_id" UUID NOT NULL,
+    "from_status" tender_status,
+    "to_status" tender_status NOT NULL,
+    "changed_by" UUID,
+    "reason" TEXT,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (tender_id) REFERENCES tenders(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS "tender_status_history_tender_id_idx" ON "tender_status_history"("tender_id", "created_at");
+
+-- the current status of existing tenders is all that is known about them
+INSERT INTO "tender_status_history" ("id", "tender_id", "to_status")
+SELECT uuid_generate_v4(), "id", "status" FROM "tenders";
+
+-- +goose Down
+DROP TABLE IF EXISTS "tender_status_history";
+
+CREATE TYPE tender_status_v1 AS ENUM (
+    'open',
+    'closed',
+    'awarded'
+);
+
+ALTER TABLE "tenders"
+    ALTER COLUMN "status" TYPE tender_status_v1
+    USING (CASE "status"::TEXT
+        WHEN 'draft' THEN 'open'
+        WHEN 'published' THEN 'open'
+        WHEN 'under_evaluation' THEN 'closed'
+        WHEN 'cancelled' THEN 'closed'
+        ELSE "status"::TEXT
+    END)::tender_status_v1;
+
+DROP TYPE tender_status;
+ALTER TYPE tender_status_v1 RENAME TO tender_status;
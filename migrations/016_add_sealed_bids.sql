@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "sealed" BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "bids_opened_at" TIMESTAMP;
+
+-- sealed bids keep price, delivery time and comment encrypted in sealed_data
+-- until the tender closes
+ALTER TABLE "bids" ADD COLUMN IF NOT EXISTS "sealed_data" TEXT;
+ALTER TABLE "bids" ALTER COLUMN "price" DROP NOT NULL;
+ALTER TABLE "bids" ALTER COLUMN "delivery_time" DROP NOT NULL;
+
+-- +goose Down
+DELETE FROM "bids" WHERE "sealed_data" IS NOT NULL;
+
+ALTER TABLE "bids" ALTER COLUMN "delivery_time" SET NOT NULL;
+ALTER TABLE "bids" ALTER COLUMN "price" SET NOT NULL;
+ALTER TABLE "bids" DROP COLUMN IF EXISTS "sealed_data";
+
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "bids_opened_at";
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "sealed";
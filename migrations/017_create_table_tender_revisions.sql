@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "revision" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "material_revision" INTEGER NOT NULL DEFAULT 0;
+
+-- the latest material amendment the bidder has acknowledged
+ALTER TABLE "bids" ADD COLUMN IF NOT EXISTS "acknowledged_revision" INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "tender_revisions"(
+    "id" UUID PRIMARY KEY,
+    "tender_id" UUID NOT NULL,
+    "number" INTEGER NOT NULL,
+    "material" BOOLEAN NOT NULL,
+    "changes" JSONB NOT NULL,
+    "reason" TEXT,
+    "changed_by" UUID,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (tender_id, number),
+    FOREIGN KEY (tender_id) REFERENCES tenders(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS "tender_revisions";
+
+ALTER TABLE "bids" DROP COLUMN IF EXISTS "acknowledged_revision";
+
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "material_revision";
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "revision";
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "tender_clarifications"(
+    "id" UUID PRIMARY KEY,
+    "tender_id" UUID NOT NULL,
+    "organization_id" UUID NOT NULL,
+    "asked_by" UUID,
+    "question" TEXT NOT NULL,
+    "answer" TEXT,
+    "answered_by" UUID,
+    "answered_at" TIMESTAMP,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (tender_id) REFERENCES tenders(id) ON DELETE CASCADE,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (asked_by) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (answered_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS "tender_clarifications_tender_id_idx" ON "tender_clarifications"("tender_id", "created_at");
+
+-- +goose Down
+DROP TABLE IF EXISTS "tender_clarifications";
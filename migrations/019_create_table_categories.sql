@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "categories"(
+    "id" UUID PRIMARY KEY,
+    "parent_id" UUID,
+    "name" VARCHAR(128) NOT NULL,
+    "slug" VARCHAR(128) NOT NULL UNIQUE,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS "categories_parent_id_idx" ON "categories"("parent_id");
+
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "category_id" UUID REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "tags" TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS "tenders_category_id_idx" ON "tenders"("category_id");
+CREATE INDEX IF NOT EXISTS "tenders_tags_idx" ON "tenders" USING GIN ("tags");
+
+CREATE TABLE IF NOT EXISTS "category_subscriptions"(
+    "user_id" UUID NOT NULL,
+    "category_id" UUID NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS "category_subscriptions";
+
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "tags";
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "category_id";
+
+DROP TABLE IF EXISTS "categories";
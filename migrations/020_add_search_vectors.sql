@@ -0,0 +1,54 @@
+-- +goose Up
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR;
+ALTER TABLE "bids" ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR;
+
+-- titles rank above tags, tags above the description
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION tenders_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', array_to_string(NEW.tags, ' ')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION bids_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('english', COALESCE(NEW.comment, ''));
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tenders_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, description, tags ON "tenders"
+    FOR EACH ROW EXECUTE FUNCTION tenders_search_vector();
+
+-- sealed bids get their comment when they are opened, which updates it
+CREATE TRIGGER bids_search_vector_update
+    BEFORE INSERT OR UPDATE OF comment ON "bids"
+    FOR EACH ROW EXECUTE FUNCTION bids_search_vector();
+
+UPDATE "tenders" SET "search_vector" =
+    setweight(to_tsvector('english', COALESCE("title", '')), 'A') ||
+    setweight(to_tsvector('english', array_to_string("tags", ' ')), 'B') ||
+    setweight(to_tsvector('english', COALESCE("description", '')), 'C');
+
+UPDATE "bids" SET "search_vector" = to_tsvector('english', COALESCE("comment", ''));
+
+CREATE INDEX IF NOT EXISTS "tenders_search_vector_idx" ON "tenders" USING GIN ("search_vector");
+CREATE INDEX IF NOT EXISTS "bids_search_vector_idx" ON "bids" USING GIN ("search_vector");
+
+-- +goose Down
+DROP TRIGGER IF EXISTS bids_search_vector_update ON "bids";
+DROP TRIGGER IF EXISTS tenders_search_vector_update ON "tenders";
+
+DROP FUNCTION IF EXISTS bids_search_vector();
+DROP FUNCTION IF EXISTS tenders_search_vector();
+
+ALTER TABLE "bids" DROP COLUMN IF EXISTS "search_vector";
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "search_vector";
@@ -0,0 +1,15 @@
+-- +goose Up
+-- tenders created before this migration get the time it ran
+ALTER TABLE "tenders" ADD COLUMN IF NOT EXISTS "created_at" TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- the id breaks ties so that pages never overlap
+CREATE INDEX IF NOT EXISTS "tenders_created_at_idx" ON "tenders"("created_at", "id");
+CREATE INDEX IF NOT EXISTS "tenders_deadline_idx" ON "tenders"("deadline", "id");
+CREATE INDEX IF NOT EXISTS "tenders_budget_idx" ON "tenders"("budget", "id");
+
+-- +goose Down
+DROP INDEX IF EXISTS "tenders_budget_idx";
+DROP INDEX IF EXISTS "tenders_deadline_idx";
+DROP INDEX IF EXISTS "tenders_created_at_idx";
+
+ALTER TABLE "tenders" DROP COLUMN IF EXISTS "created_at";
@@ -0,0 +1,12 @@
+-- +goose Up
+-- bids created before this migration get the time it ran
+ALTER TABLE "bids" ADD COLUMN IF NOT EXISTS "created_at" TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS "bids_created_at_idx" ON "bids"("created_at", "id");
+CREATE INDEX IF NOT EXISTS "bids_tender_id_created_at_idx" ON "bids"("tender_id", "created_at", "id");
+
+-- +goose Down
+DROP INDEX IF EXISTS "bids_tender_id_created_at_idx";
+DROP INDEX IF EXISTS "bids_created_at_idx";
+
+ALTER TABLE "bids" DROP COLUMN IF EXISTS "created_at";
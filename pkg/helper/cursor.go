@@ -0,0 +1,22 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// EncodeCursor turns a list position into an opaque string for clients
+func EncodeCursor(cursor any) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor into cursor
+func DecodeCursor(encoded string, cursor any) error {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, cursor)
+}
@@ -1,20 +1,117 @@
 package helper
 
 import (
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"tender-bridge/config"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters used for newly generated hashes. Stored hashes carry
+// their own parameters, so these can be raised without breaking old ones.
+const (
+	argonTime    uint32 = 1
+	argonMemory  uint32 = 64 * 1024
+	argonThreads uint8  = 4
+	argonKeyLen  uint32 = 32
+	argonSaltLen        = 16
 )
 
+var errInvalidHash = errors.New("invalid password hash format")
+
+// GenerateHash returns a per-password salted Argon2id hash encoded as
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
 func GenerateHash(password string) (string, error) {
 	if password == "" {
 		return "", errors.New("password cannot empty")
 	}
 
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argonMemory,
+		argonTime,
+		argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// ComparePassword reports whether the password matches the stored hash. The
+// second result is true when the hash should be regenerated with
+// GenerateHash, either because it is a legacy SHA-1 hash or because it was
+// created with weaker parameters than the current ones.
+func ComparePassword(hash, password string) (bool, bool, error) {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		legacy := generateLegacyHash(password)
+		match := subtle.ConstantTimeCompare([]byte(hash), []byte(legacy)) == 1
+		return match, match, nil
+	}
+
+	var (
+		version            int
+		memory, iterations uint32
+		threads            uint8
+	)
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, errInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, errInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, false, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, errInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, errInvalidHash
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false, nil
+	}
+
+	rehash := memory < argonMemory || iterations < argonTime || threads < argonThreads || uint32(len(key)) < argonKeyLen
+
+	return true, rehash, nil
+}
+
+// generateLegacyHash reproduces the SHA-1 scheme used before Argon2id, so
+// that existing users can still log in once and get their hash upgraded.
+func generateLegacyHash(password string) string {
 	salt := config.GetConfig().HashKey
 	hash := sha1.New()
 	hash.Write([]byte(password))
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt))), nil
+	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+}
+
+// HashToken returns a hex encoded SHA-256 digest of an opaque token so that
+// it can be stored and looked up without keeping the token itself.
+func HashToken(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
 }
@@ -0,0 +1,295 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tender-bridge/config"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// keyFileExt is the extension of key files in the keys directory. The file
+// name without it is used as the key id.
+const keyFileExt = ".pem"
+
+const minRSABits = 2048
+
+var (
+	errUnknownKey     = errors.New("unknown signing key")
+	errNoSigningKey   = errors.New("no private key to sign tokens with")
+	errUnsupportedKey = errors.New("unsupported key type, expected RSA or Ed25519")
+)
+
+// Key is a single signing or verification key
+type Key struct {
+	Id     string
+	Method jwt.SigningMethod
+
+	// private is nil for keys that are only kept to verify tokens signed
+	// before a rotation
+	private interface{}
+	public  interface{}
+
+	// notAfter ends the verification of tokens signed with the key. It is
+	// zero for keys without an end.
+	notAfter time.Time
+}
+
+// KeySet holds every key tokens may be signed with and the one currently
+// used for signing
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+// JSONWebKey is the public part of a key as described in RFC 7517
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewKeySet loads the keys configured in cfg. Without a keys directory the
+// shared JWTSecret is used with HS256, which keeps existing deployments
+// working but cannot be published for third party verification. With one,
+// JWTSecret still verifies the tokens issued before the switch until
+// JWTLegacySecretUntil.
+func NewKeySet(cfg *config.Config) (*KeySet, error) {
+	if cfg.JWTKeysDir == "" {
+		return NewHMACKeySet(cfg.JWTSecret), nil
+	}
+
+	set, err := LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.JWTLegacySecretUntil == "" {
+		return set, nil
+	}
+
+	until, err := time.Parse(time.RFC3339, cfg.JWTLegacySecretUntil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_LEGACY_SECRET_UNTIL: %w", err)
+	}
+
+	if cfg.JWTSecret != "" && time.Now().Before(until) {
+		set.AddLegacySecret(cfg.JWTSecret, until)
+	}
+
+	return set, nil
+}
+
+func NewHMACKeySet(secret string) *KeySet {
+	key := &Key{
+		Method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+
+	return &KeySet{
+		keys:    map[string]*Key{"": key},
+		signing: key,
+	}
+}
+
+// AddLegacySecret keeps accepting tokens signed with the HS256 secret used
+// before the switch to a keys directory. The secret never signs and stops
+// verifying at until, which should be at least the longest refresh token
+// lifetime after the switch.
+func (s *KeySet) AddLegacySecret(secret string, until time.Time) {
+	s.keys[""] = &Key{
+		Method:   jwt.SigningMethodHS256,
+		public:   []byte(secret),
+		notAfter: until,
+	}
+}
+
+// LoadKeySet reads every <kid>.pem file in dir. Files holding a private key
+// can sign and verify, files holding only a public key verify tokens signed
+// before the last rotation. signingKeyId may be empty when the directory
+// holds a single private key.
+func LoadKeySet(dir, signingKeyId string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{keys: make(map[string]*Key)}
+	privateIds := []string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), keyFileExt)
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+
+		set.keys[id] = key
+		if key.private != nil {
+			privateIds = append(privateIds, id)
+		}
+	}
+
+	if signingKeyId == "" {
+		if len(privateIds) != 1 {
+			return nil, fmt.Errorf("%w: set the signing key id when the directory holds %d private keys", errNoSigningKey, len(privateIds))
+		}
+		signingKeyId = privateIds[0]
+	}
+
+	signing, ok := set.keys[signingKeyId]
+	if !ok || signing.private == nil {
+		return nil, fmt.Errorf("%w: %s", errNoSigningKey, signingKeyId)
+	}
+	set.signing = signing
+
+	return set, nil
+}
+
+func parseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{Id: id}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, errUnsupportedKey
+	}
+
+	if public, ok := key.public.(*rsa.PublicKey); ok && public.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+	}
+
+	return key, nil
+}
+
+// Sign signs the claims with the current signing key and records its id in
+// the kid header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.Id != "" {
+		token.Header["kid"] = s.signing.Id
+	}
+
+	return token.SignedString(s.signing.private)
+}
+
+// Keyfunc picks the verification key named by the token's kid header. The
+// algorithm must be the one of that key, so a public RSA key can never be
+// used as an HMAC secret.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+
+	key, ok := s.keys[id]
+	if !ok || (!key.notAfter.IsZero() && time.Now().After(key.notAfter)) {
+		return nil, errUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key.public, nil
+}
+
+// PublicKeys returns the verification keys in JWKS form. Shared HMAC
+// secrets are never published.
+func (s *KeySet) PublicKeys() JSONWebKeySet {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, id := range ids {
+		key := s.keys[id]
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				Kty: "RSA",
+				Kid: id,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				Kty: "OKP",
+				Kid: id,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,88 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"tender-bridge/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestLegacySecret(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ed-1.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.StandardClaims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	// a token issued before the switch to the keys directory
+	legacy, err := NewHMACKeySet("old-secret").Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(set *KeySet, raw string) error {
+		_, err := jwt.Parse(raw, set.Keyfunc)
+		return err
+	}
+
+	cases := []struct {
+		name  string
+		until string
+		ok    bool
+	}{
+		{name: "within the window", until: time.Now().Add(time.Hour).Format(time.RFC3339), ok: true},
+		{name: "after the window", until: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+		{name: "without a window"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			set, err := NewKeySet(&config.Config{
+				JWTSecret:            "old-secret",
+				JWTKeysDir:           dir,
+				JWTLegacySecretUntil: tc.until,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := parse(set, legacy); (err == nil) != tc.ok {
+				t.Fatalf("legacy token accepted = %v, want %v (%v)", err == nil, tc.ok, err)
+			}
+
+			current, err := set.Sign(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := parse(set, current); err != nil {
+				t.Fatalf("token of the signing key rejected: %v", err)
+			}
+
+			if keys := set.PublicKeys().Keys; len(keys) != 1 || keys[0].Kid != "ed-1" {
+				t.Errorf("published keys %+v", keys)
+			}
+		})
+	}
+
+	if _, err := NewKeySet(&config.Config{JWTSecret: "old-secret", JWTKeysDir: dir, JWTLegacySecretUntil: "next week"}); err == nil {
+		t.Error("an invalid legacy window was accepted")
+	}
+}
@@ -0,0 +1,439 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	httpTimeout = 10 * time.Second
+
+	// clockSkew is tolerated between us and the identity provider
+	clockSkew = time.Minute
+
+	// keysRefreshInterval limits how often an unknown kid can trigger a new
+	// JWKS download
+	keysRefreshInterval = time.Minute
+)
+
+var (
+	errUnknownKey   = errors.New("oidc: unknown signing key")
+	errInvalidToken = errors.New("oidc: invalid id token")
+)
+
+// Config describes one identity provider
+type Config struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientId     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+
+	// DefaultRole is given to users provisioned on their first login
+	DefaultRole string `json:"default_role"`
+
+	// LinkByEmail lets a first login attach to an existing account with the
+	// same verified email. Only enable it for providers that own the email
+	// domains of their users.
+	LinkByEmail bool `json:"link_by_email"`
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to a single identity provider. The discovery document and
+// signing keys are fetched on first use, so an unreachable provider does not
+// stop the application from starting.
+type Provider struct {
+	Config
+
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *discovery
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// IDToken holds the ID token claims used to sign a user in
+type IDToken struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	NotBefore         int64    `json:"nbf"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name"`
+}
+
+// audience accepts both the single string and the array form of aud
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+
+	return nil
+}
+
+// Valid checks the time based claims
+func (t *IDToken) Valid() error {
+	now := time.Now()
+
+	if t.ExpiresAt == 0 || now.After(time.Unix(t.ExpiresAt, 0).Add(clockSkew)) {
+		return errors.New("oidc: id token is expired")
+	}
+
+	if t.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(t.NotBefore, 0)) {
+		return errors.New("oidc: id token is not valid yet")
+	}
+
+	return nil
+}
+
+// LoadProviders reads a JSON array of provider configs. An empty path means
+// OIDC login is disabled.
+func LoadProviders(path string) (map[string]*Provider, error) {
+	providers := map[string]*Provider{}
+	if path == "" {
+		return providers, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []Config
+	if err = json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("oidc: %s: %w", path, err)
+	}
+
+	for _, cfg := range configs {
+		if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientId == "" {
+			return nil, errors.New("oidc: name, issuer and client_id are required")
+		}
+
+		if _, ok := providers[cfg.Name]; ok {
+			return nil, fmt.Errorf("oidc: duplicate provider %s", cfg.Name)
+		}
+
+		providers[cfg.Name] = NewProvider(cfg)
+	}
+
+	return providers, nil
+}
+
+func NewProvider(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	return &Provider{
+		Config: cfg,
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// CodeChallenge derives the S256 PKCE challenge of a code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the provider URL the user is sent to for signing in
+func (p *Provider) AuthCodeURL(redirectURI, state, nonce, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientId)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return d.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens and returns the raw ID
+// token
+func (p *Provider) Exchange(code, redirectURI, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.ClientId)
+
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientId), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("oidc: token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc: token exchange failed: %s %s", body.Error, body.ErrorDescription)
+	}
+
+	if body.IdToken == "" {
+		return "", errors.New("oidc: token response has no id_token")
+	}
+
+	return body.IdToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce
+// of an ID token
+func (p *Provider) VerifyIDToken(raw, nonce string) (*IDToken, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}}
+
+	claims := &IDToken{}
+	if _, err = parser.ParseWithClaims(raw, claims, p.keyfunc); err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != d.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", errInvalidToken)
+	}
+
+	if !slices.Contains(claims.Audience, p.ClientId) {
+		return nil, fmt.Errorf("%w: unexpected audience", errInvalidToken)
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientId {
+		return nil, fmt.Errorf("%w: unexpected authorized party", errInvalidToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", errInvalidToken)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", errInvalidToken)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) getDiscovery() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.getJSON(strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, err
+	}
+
+	if d.Issuer != p.Issuer {
+		return nil, fmt.Errorf("oidc: issuer %q does not match discovery issuer %q", p.Issuer, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc: incomplete discovery document")
+	}
+
+	p.discovery = &d
+
+	return p.discovery, nil
+}
+
+// keyfunc finds the provider key named by the kid header, downloading the
+// key set again when the provider has rotated its keys
+func (p *Provider) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	if !ok && time.Since(p.keysFetchedAt) > keysRefreshInterval {
+		if err = p.fetchKeys(d.JWKSURI); err != nil {
+			return nil, err
+		}
+		key, ok = p.keys[kid]
+	}
+
+	if !ok {
+		return nil, errUnknownKey
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+			return nil, errUnknownKey
+		}
+	case *ecdsa.PublicKey:
+		if token.Method.Alg() != jwt.SigningMethodES256.Alg() {
+			return nil, errUnknownKey
+		}
+	}
+
+	return key, nil
+}
+
+func (p *Provider) fetchKeys(uri string) error {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+
+	if err := p.getJSON(uri, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case k.Kty == "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return err
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return err
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return err
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return err
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		}
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	return nil
+}
+
+func (p *Provider) getJSON(uri string, dest any) error {
+	resp, err := p.client.Get(uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s: %s", uri, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid key: %w", err)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,333 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testClientId = "tender-bridge"
+	testKid      = "rsa-1"
+	testNonce    = "nonce-123"
+)
+
+// mockIdP is a local identity provider serving discovery, JWKS and a token
+// endpoint that hands out whatever ID token the test set
+type mockIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu        sync.Mutex
+	idToken   string
+	tokenForm url.Values
+	tokenAuth [2]string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.tokenForm = r.PostForm
+		user, pass, _ := r.BasicAuth()
+		m.tokenAuth = [2]string{user, pass}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.idToken})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+func (m *mockIdP) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "mock",
+		Issuer:       m.URL,
+		ClientId:     testClientId,
+		ClientSecret: "secret",
+	})
+}
+
+// claims returns valid ID token claims for the mock provider
+func (m *mockIdP) claims() jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"iss":   m.URL,
+		"sub":   "user-1",
+		"aud":   testClientId,
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": testNonce,
+		"email": "user@example.com",
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestExchangeUsesPKCE(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+
+	verifier := "verifier-abc"
+
+	authURL, err := p.AuthCodeURL("https://app/callback", "state-1", testNonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := u.Query()
+	if got := query.Get("code_challenge"); got != CodeChallenge(verifier) {
+		t.Errorf("code_challenge = %q, want %q", got, CodeChallenge(verifier))
+	}
+	if got := query.Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method = %q, want S256", got)
+	}
+	if query.Get("code_verifier") != "" {
+		t.Error("the verifier must not leave the server in the authorization URL")
+	}
+	if got := query.Get("nonce"); got != testNonce {
+		t.Errorf("nonce = %q, want %q", got, testNonce)
+	}
+
+	idp.idToken = sign(t, jwt.SigningMethodRS256, testKid, idp.claims(), idp.key)
+
+	raw, err := p.Exchange("code-1", "https://app/callback", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw != idp.idToken {
+		t.Error("Exchange did not return the ID token of the provider")
+	}
+
+	want := map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          "code-1",
+		"redirect_uri":  "https://app/callback",
+		"code_verifier": verifier,
+		"client_id":     testClientId,
+	}
+	for name, value := range want {
+		if got := idp.tokenForm.Get(name); got != value {
+			t.Errorf("token request %s = %q, want %q", name, got, value)
+		}
+	}
+
+	if idp.tokenAuth != [2]string{testClientId, "secret"} {
+		t.Errorf("token request basic auth = %v", idp.tokenAuth)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := idp.claims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+
+	// the RSA modulus is public, a verifier using it as an HMAC secret would
+	// accept tokens anyone can make
+	hmacKey := idp.key.PublicKey.N.Bytes()
+
+	cases := []struct {
+		name  string
+		token string
+		nonce string
+		ok    bool
+	}{
+		{
+			name:  "valid",
+			token: sign(t, jwt.SigningMethodRS256, testKid, idp.claims(), idp.key),
+			ok:    true,
+		},
+		{
+			name:  "audience list with authorized party",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"aud": []string{testClientId, "other"}, "azp": testClientId}), idp.key),
+			ok:    true,
+		},
+		{
+			name:  "wrong issuer",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"iss": "https://evil.example.com"}), idp.key),
+		},
+		{
+			name:  "wrong audience",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"aud": "other-client"}), idp.key),
+		},
+		{
+			name:  "audience list without authorized party",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"aud": []string{testClientId, "other"}}), idp.key),
+		},
+		{
+			name:  "wrong authorized party",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"aud": []string{testClientId, "other"}, "azp": "other"}), idp.key),
+		},
+		{
+			name:  "nonce mismatch",
+			token: sign(t, jwt.SigningMethodRS256, testKid, idp.claims(), idp.key),
+			nonce: "another-nonce",
+		},
+		{
+			name:  "missing nonce",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"nonce": nil}), idp.key),
+		},
+		{
+			name:  "expired",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"exp": time.Now().Add(-2 * clockSkew).Unix()}), idp.key),
+		},
+		{
+			name:  "missing expiry",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"exp": nil}), idp.key),
+		},
+		{
+			name:  "not valid yet",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"nbf": time.Now().Add(2 * clockSkew).Unix()}), idp.key),
+		},
+		{
+			name:  "missing subject",
+			token: sign(t, jwt.SigningMethodRS256, testKid, with(jwt.MapClaims{"sub": nil}), idp.key),
+		},
+		{
+			name:  "unknown kid",
+			token: sign(t, jwt.SigningMethodRS256, "rsa-2", idp.claims(), otherKey),
+		},
+		{
+			name:  "signed with another key",
+			token: sign(t, jwt.SigningMethodRS256, testKid, idp.claims(), otherKey),
+		},
+		{
+			name:  "alg does not match the key type",
+			token: sign(t, jwt.SigningMethodES256, testKid, idp.claims(), ecKey),
+		},
+		{
+			name:  "alg not allowed",
+			token: sign(t, jwt.SigningMethodHS256, testKid, idp.claims(), hmacKey),
+		},
+		{
+			name:  "alg none",
+			token: sign(t, jwt.SigningMethodNone, testKid, idp.claims(), jwt.UnsafeAllowNoneSignatureType),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nonce := tc.nonce
+			if nonce == "" {
+				nonce = testNonce
+			}
+
+			claims, err := idp.provider().VerifyIDToken(tc.token, nonce)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims.Subject != "user-1" || claims.Email != "user@example.com" {
+					t.Errorf("unexpected claims %+v", claims)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("token was accepted")
+			}
+		})
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+
+	p := NewProvider(Config{
+		Name:     "mock",
+		Issuer:   strings.Replace(idp.URL, "127.0.0.1", "localhost", 1),
+		ClientId: testClientId,
+	})
+
+	if _, err := p.AuthCodeURL("https://app/callback", "state", "nonce", "verifier"); err == nil {
+		t.Fatal("a discovery document for another issuer was accepted")
+	}
+}
@@ -0,0 +1,67 @@
+package sealer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+var errInvalidSealedData = errors.New("invalid sealed data")
+
+// Sealer encrypts data with AES-256-GCM under a key derived from a secret.
+// The additional data passed to Seal must be given again to Open, which ties
+// a sealed value to the record it belongs to.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+func NewSealer(secret string) (*Sealer, error) {
+	if secret == "" {
+		return nil, errors.New("sealer secret is empty")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts plaintext and returns the nonce and ciphertext base64 encoded
+func (s *Sealer) Seal(plaintext, additionalData []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, plaintext, additionalData)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal
+func (s *Sealer) Open(sealed string, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, errInvalidSealedData
+	}
+
+	if len(data) < s.aead.NonceSize() {
+		return nil, errInvalidSealedData
+	}
+
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+
+	return s.aead.Open(nil, nonce, ciphertext, additionalData)
+}
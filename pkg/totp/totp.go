@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters shared with authenticator apps. SHA-1, 6 digits and a 30 second
+// period are the defaults every common app supports.
+const (
+	Period     = 30
+	Digits     = 6
+	secretSize = 20
+
+	// number of periods accepted on each side of the current one to tolerate
+	// clock drift between the server and the device
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded shared secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI builds the otpauth:// URI that authenticator apps read from a QR code
+func URI(secret, issuer, account string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(Digits))
+	values.Set("period", fmt.Sprint(Period))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, values.Encode())
+}
+
+// Code returns the code for the period containing t
+func Code(secret string, t time.Time) (string, error) {
+	return code(secret, uint64(t.Unix()/Period))
+}
+
+// Validate checks the code against the periods around t and returns the
+// matched period, so callers can refuse a code that has already been used.
+// The passcode must be exactly Digits long, surrounding spaces are refused.
+func Validate(secret, passcode string, t time.Time) (uint64, bool) {
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	current := t.Unix() / Period
+	for i := -skew; i <= skew; i++ {
+		counter := uint64(current + int64(i))
+
+		expected, err := code(secret, counter)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(passcode)) == 1 {
+			return counter, true
+		}
+	}
+
+	return 0, false
+}
+
+// code implements HOTP (RFC 4226) for the given counter
+func code(secret string, counter uint64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
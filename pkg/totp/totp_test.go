@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors, base32 encoded
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1, truncated from 8 to 6 digits
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tc := range cases {
+		got, err := Code(rfcSecret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("Code at %d = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+
+	if _, err := Code("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("an invalid secret was accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := uint64(now.Unix() / Period)
+
+	at := func(periods int) string {
+		code, err := Code(rfcSecret, now.Add(time.Duration(periods*Period)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	cases := []struct {
+		name     string
+		passcode string
+		ok       bool
+		counter  uint64
+	}{
+		{name: "current period", passcode: at(0), ok: true, counter: current},
+		{name: "previous period", passcode: at(-1), ok: true, counter: current - 1},
+		{name: "next period", passcode: at(1), ok: true, counter: current + 1},
+		{name: "two periods ago", passcode: at(-2)},
+		{name: "two periods ahead", passcode: at(2)},
+		{name: "surrounding spaces", passcode: " " + at(0) + " "},
+		{name: "trailing newline", passcode: at(0) + "\n"},
+		{name: "too short", passcode: at(0)[:Digits-1]},
+		{name: "too long", passcode: at(0) + "0"},
+		{name: "empty", passcode: ""},
+		{name: "inner space", passcode: at(0)[:3] + " " + at(0)[3:]},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			counter, ok := Validate(rfcSecret, tc.passcode, now)
+			if ok != tc.ok {
+				t.Fatalf("Validate(%q) = %v, want %v", tc.passcode, ok, tc.ok)
+			}
+			if ok && counter != tc.counter {
+				t.Errorf("matched period %d, want %d", counter, tc.counter)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package validator
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"tender-bridge/config"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordLength keeps hashing cost bounded for absurdly long inputs
+const maxPasswordLength = 128
+
+// PasswordPolicy decides whether a password is strong enough to be set
+type PasswordPolicy struct {
+	MinLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+
+	// breached holds lowercased plain passwords and uppercase SHA-1 hashes
+	// of known leaked passwords
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy builds the policy from cfg and loads the breached
+// password list when one is configured
+func NewPasswordPolicy(cfg *config.Config) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		MinLength:        cfg.PasswordMinLength,
+		RequireLowercase: cfg.PasswordRequireLowercase,
+		RequireUppercase: cfg.PasswordRequireUppercase,
+		RequireDigit:     cfg.PasswordRequireDigit,
+		RequireSymbol:    cfg.PasswordRequireSymbol,
+		breached:         map[string]struct{}{},
+	}
+
+	if cfg.PasswordBreachedListFile == "" {
+		return policy, nil
+	}
+
+	if err := policy.loadBreached(cfg.PasswordBreachedListFile); err != nil {
+		return nil, fmt.Errorf("breached password list: %w", err)
+	}
+
+	return policy, nil
+}
+
+// loadBreached reads one password per line. Lines may also be SHA-1 hashes
+// in the "HASH" or "HASH:COUNT" form of the Pwned Passwords downloads.
+func (p *PasswordPolicy) loadBreached(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			p.breached[strings.ToUpper(hash)] = struct{}{}
+			continue
+		}
+
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Validate checks password against the policy. personal holds values the
+// password must not contain, such as the username and email. The error names
+// field and never echoes the password.
+func (p *PasswordPolicy) Validate(field, password string, personal ...string) error {
+	length := utf8.RuneCountInString(password)
+
+	if length < p.MinLength {
+		return fmt.Errorf("%s: must be at least %d characters long", field, p.MinLength)
+	}
+
+	if length > maxPasswordLength {
+		return fmt.Errorf("%s: must be less than %d characters", field, maxPasswordLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case p.RequireLowercase && !lower:
+		return passwordError(field, "lowercase")
+	case p.RequireUppercase && !upper:
+		return passwordError(field, "uppercase")
+	case p.RequireDigit && !digit:
+		return passwordError(field, "numeric")
+	case p.RequireSymbol && !symbol:
+		return passwordError(field, "symbol")
+	}
+
+	folded := strings.ToLower(password)
+	for _, value := range personal {
+		// for emails only the part before @ is personal, the domain is
+		// shared with everyone else at the company
+		value, _, _ = strings.Cut(strings.ToLower(value), "@")
+		if len(value) >= 3 && strings.Contains(folded, value) {
+			return passwordError(field, "personal")
+		}
+	}
+
+	if p.isBreached(password) {
+		return passwordError(field, "breached")
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if len(p.breached) == 0 {
+		return false
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return true
+	}
+
+	sum := sha1.Sum([]byte(password))
+	_, ok := p.breached[strings.ToUpper(hex.EncodeToString(sum[:]))]
+
+	return ok
+}
+
+func passwordError(field, tag string) error {
+	return fmt.Errorf("%s: %s", field, mapHelepr[tag])
+}
+
+func isSHA1Hex(value string) bool {
+	if len(value) != sha1.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tender-bridge/config"
+	"testing"
+)
+
+// breachedList mixes the forms the loader accepts: a comment, a plain
+// password and a lowercase SHA-1 hash of "Winter2024!" with a count
+const breachedList = `# leaked passwords
+Summer2024!
+
+fcb8f40140297c7d1e3464c53e1f9a8bc4ddbedf:42
+`
+
+func newTestPolicy(t *testing.T) *PasswordPolicy {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(breachedList), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := NewPasswordPolicy(&config.Config{
+		PasswordMinLength:        8,
+		PasswordRequireLowercase: true,
+		PasswordRequireUppercase: true,
+		PasswordRequireDigit:     true,
+		PasswordRequireSymbol:    true,
+		PasswordBreachedListFile: path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return policy
+}
+
+type passwordCase struct {
+	name     string
+	password string
+	personal []string
+
+	// want is the expected message after the field name, empty when the
+	// password is accepted
+	want string
+}
+
+var passwordCases = []passwordCase{
+	{name: "strong password", password: "Tender-Bridge9", want: ""},
+
+	// length is counted in runes, not bytes
+	{name: "too short", password: "Aa1!aaa", want: "must be at least 8 characters long"},
+	{name: "too short in runes though long in bytes", password: "Ää1!ääé", want: "must be at least 8 characters long"},
+	{name: "minimum length", password: "Aa1!aaaa", want: ""},
+	{name: "maximum length in runes", password: "Aa1!" + strings.Repeat("ä", maxPasswordLength-4), want: ""},
+	{name: "too long", password: "Aa1!" + strings.Repeat("a", maxPasswordLength-3), want: fmt.Sprintf("must be less than %d characters", maxPasswordLength)},
+
+	// each required character class
+	{name: "missing lowercase", password: "TENDER-BRIDGE9", want: mapHelepr["lowercase"]},
+	{name: "missing uppercase", password: "tender-bridge9", want: mapHelepr["uppercase"]},
+	{name: "missing digit", password: "Tender-Bridge", want: mapHelepr["numeric"]},
+	{name: "missing symbol", password: "TenderBridge9", want: mapHelepr["symbol"]},
+	{name: "space counts as symbol", password: "Tender Bridge9", want: ""},
+	{name: "non-latin letters count", password: "Тендер-мост9", want: ""},
+	{name: "non-latin lowercase only", password: "тендер-мост9", want: mapHelepr["uppercase"]},
+
+	// username and email
+	{name: "contains the username", password: "xJohnny-77", personal: []string{"johnny"}, want: mapHelepr["personal"]},
+	{name: "contains the username in another case", password: "JOHNNY-77x", personal: []string{"Johnny"}, want: mapHelepr["personal"]},
+	{name: "contains the email local part", password: "Mary.Jane-7", personal: []string{"", "mary.jane@acme.com"}, want: mapHelepr["personal"]},
+	{name: "email domain is ignored", password: "Acme.com-77", personal: []string{"mary.jane@acme.com"}, want: ""},
+	{name: "short username is skipped", password: "Jo-Bridge9", personal: []string{"jo"}, want: ""},
+	{name: "short email local part is skipped", password: "Al-Bridge9", personal: []string{"al@acme.com"}, want: ""},
+
+	// breached list
+	{name: "breached plain password", password: "Summer2024!", want: mapHelepr["breached"]},
+	{name: "breached plain password in another case", password: "sUMMER2024!", want: mapHelepr["breached"]},
+	{name: "breached by SHA-1", password: "Winter2024!", want: mapHelepr["breached"]},
+	{name: "SHA-1 match is case-sensitive", password: "wINTER2024!", want: ""},
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := newTestPolicy(t)
+
+	for _, tc := range passwordCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate("new_password", tc.password, tc.personal...)
+
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("password was accepted")
+			}
+			if want := "new_password: " + tc.want; err.Error() != want {
+				t.Errorf("error %q, want %q", err, want)
+			}
+			if strings.Contains(err.Error(), tc.password) {
+				t.Error("the error echoes the password")
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyWithoutRequirements(t *testing.T) {
+	policy, err := NewPasswordPolicy(&config.Config{PasswordMinLength: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, password := range []string{"aaaaaaaa", "12345678", "Summer2024!"} {
+		if err := policy.Validate("password", password); err != nil {
+			t.Errorf("Validate(%q) = %v", password, err)
+		}
+	}
+
+	if _, err := NewPasswordPolicy(&config.Config{PasswordBreachedListFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("a missing breached list was accepted")
+	}
+}
@@ -16,6 +16,9 @@ var mapHelepr = map[string]string{
 	"lowercase":  "must contain at least one lowercase letter",
 	"uppercase":  "must contain at least one uppercase letter",
 	"numeric":    "must contain at least one digit",
+	"symbol":     "must contain at least one symbol",
+	"personal":   "must not contain the username or email",
+	"breached":   "appears in a list of leaked passwords, choose another one",
 	"uzbphone":   "is not a valid phone number",
 	"customDate": "is not a valid date format",
 }